@@ -1,10 +1,12 @@
 // Command databridge-gen generates reflection-free binders for url.Values (forms)
 // into your struct types. Prototype: supports flat fields, nested structs,
-// basic slices, and common primitives.
+// basic slices, and common primitives. With -schema it also writes the JSON
+// Schema of each type (see databridge.SchemaFor) to <dir>/<Type>.schema.json.
 //
 // Usage:
 //
 //	go run ./cmd/databridge-gen -types Person,Address -out zz_databridge_gen.go
+//	go run ./cmd/databridge-gen -types Person -schema schemas
 package main
 
 import (
@@ -16,6 +18,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -31,15 +34,27 @@ type Field struct {
 	ElemExpr string // for slices
 	Children []Field
 	IsTime   bool
+	IsDur    bool
+	Layout   string // from `databridge:"layout=..."`
 }
 
+// runtimePkgPath is the import path of the databridge runtime whose ParseTime and
+// ParseDuration the generated code calls.
+const runtimePkgPath = "github.com/dataBridgeGoPkg/dataBridge"
+
+// runtimeQual qualifies calls into the runtime ("databridge." or "" when
+// generating into the databridge package itself).
+var runtimeQual = "databridge."
+
 func main() {
 	var typesCSV string
 	var out string
 	var pkgDir string
+	var schemaDir string
 	flag.StringVar(&typesCSV, "types", "", "comma-separated list of type names to generate binders for")
 	flag.StringVar(&out, "out", "zz_databridge_gen.go", "output file name")
 	flag.StringVar(&pkgDir, "pkgdir", ".", "package directory to load (default: current)")
+	flag.StringVar(&schemaDir, "schema", "", "directory to write <Type>.schema.json JSON Schemas to (optional)")
 	flag.Parse()
 
 	if typesCSV == "" {
@@ -47,19 +62,23 @@ func main() {
 	}
 	typeNames := splitCSV(typesCSV)
 
-	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo, Dir: pkgDir}
-	pkgs, err := packages.Load(cfg, "./")
-	if err != nil || packages.PrintErrors(pkgs) > 0 {
-		log.Fatalf("failed to load package: %v", err)
-	}
-	if len(pkgs) == 0 {
-		log.Fatal("no package found")
+	pkg, err := loadPackage(pkgDir)
+	if err != nil {
+		log.Fatal(err)
 	}
-	pkg := pkgs[0]
 
 	// Find package name
 	pkgName := pkg.Name
 
+	if schemaDir != "" {
+		if !filepath.IsAbs(schemaDir) {
+			schemaDir = filepath.Join(pkgDir, schemaDir)
+		}
+		if err := writeSchemas(pkg.Types, pkgDir, typeNames, schemaDir); err != nil {
+			log.Fatalf("schema error: %v", err)
+		}
+	}
+
 	// Build AST field info by scanning syntax for named types
 	fieldsByType := map[string][]Field{}
 
@@ -89,6 +108,12 @@ func main() {
 		})
 	}
 
+	// time and duration parsing is delegated to the runtime so generated binders
+	// accept exactly the same layouts and epochs as Transform
+	if pkg.PkgPath == runtimePkgPath {
+		runtimeQual = ""
+	}
+
 	// Generate
 	var b strings.Builder
 	b.WriteString("// Code generated by databridge-gen; DO NOT EDIT.\n")
@@ -96,17 +121,10 @@ func main() {
 	b.WriteString("import (\n")
 	b.WriteString("\t\"net/url\"\n")
 	b.WriteString("\t\"strconv\"\n")
-	b.WriteString("\t\"time\"\n")
 	b.WriteString(")\n\n")
 
-	// helper for bool/int/float parsing and time parsing
+	// helper for bool parsing
 	b.WriteString("func _db_parseBool(s string) (bool, error) { return strconv.ParseBool(strings.TrimSpace(s)) }\n")
-	b.WriteString("func _db_parseTimeFlexible(s string) (time.Time, bool) {\n")
-	b.WriteString("\ts = strings.TrimSpace(s)\n")
-	b.WriteString("\tif s == \"\" { return time.Time{}, false }\n")
-	b.WriteString("\tformats := []string{time.RFC3339Nano, time.RFC3339, \"2006-01-02 15:04:05\", \"2006-01-02\"}\n")
-	b.WriteString("\tfor _, f := range formats { if t, err := time.Parse(f, s); err == nil { return t, true } }\n")
-	b.WriteString("\treturn time.Time{}, false\n}\n")
 	// strings is needed; we'll ensure import presence if used
 
 	// Build functions for each type
@@ -133,6 +151,9 @@ func main() {
 	if strings.Contains(outSrc, "strings.") && !strings.Contains(outSrc, "\"strings\"") {
 		outSrc = strings.Replace(outSrc, "import (\n\t\"net/url\"\n", "import (\n\t\"net/url\"\n\t\"strings\"\n", 1)
 	}
+	if runtimeQual != "" && strings.Contains(outSrc, runtimeQual) {
+		outSrc = strings.Replace(outSrc, "import (\n", "import (\n\tdatabridge \""+runtimePkgPath+"\"\n", 1)
+	}
 
 	// gofmt
 	formatted, err := format.Source([]byte(outSrc))
@@ -150,6 +171,19 @@ func main() {
 	}
 }
 
+// loadPackage loads the package in dir with its syntax and types.
+func loadPackage(dir string) (*packages.Package, error) {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps, Dir: dir}
+	pkgs, err := packages.Load(cfg, "./")
+	if err != nil || packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("failed to load package: %v", err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no package found")
+	}
+	return pkgs[0], nil
+}
+
 func splitCSV(s string) []string {
 	parts := strings.Split(s, ",")
 	out := make([]string, 0, len(parts))
@@ -199,6 +233,27 @@ func jsonNameFromTag(tag string, fallback string) string {
 	return value
 }
 
+// layoutFromTag returns the time layout of a `databridge:"layout=..."` tag option.
+// Layouts containing commas are single-quoted: layout='Jan 2, 2006'.
+func layoutFromTag(tag string) string {
+	v := reflect.StructTag(tag).Get("databridge")
+	i := strings.Index(v, "layout=")
+	if i < 0 {
+		return ""
+	}
+	rest := v[i+len("layout="):]
+	if strings.HasPrefix(rest, "'") {
+		if j := strings.Index(rest[1:], "'"); j >= 0 {
+			return rest[1 : j+1]
+		}
+		return rest[1:]
+	}
+	if j := strings.Index(rest, ","); j >= 0 {
+		return rest[:j]
+	}
+	return rest
+}
+
 func collectFields(fl *ast.FieldList, parent string) []Field {
 	var res []Field
 	if fl == nil {
@@ -229,12 +284,15 @@ func collectFields(fl *ast.FieldList, parent string) []Field {
 			}
 			res = append(res, fe)
 		case *ast.SelectorExpr:
-			// e.g., time.Time
+			// e.g., time.Time, time.Duration
 			pkgIdent, ok := t.X.(*ast.Ident)
 			if ok && pkgIdent.Name == "time" && t.Sel.Name == "Time" {
-				fe := Field{Name: name, JSONName: jname, TypeExpr: "time.Time", IsTime: true}
+				fe := Field{Name: name, JSONName: jname, TypeExpr: "time.Time", IsTime: true, Layout: layoutFromTag(tag)}
 				res = append(res, fe)
 			}
+			if ok && pkgIdent.Name == "time" && t.Sel.Name == "Duration" {
+				res = append(res, Field{Name: name, JSONName: jname, TypeExpr: "time.Duration", IsDur: true})
+			}
 		case *ast.StarExpr:
 			switch et := t.X.(type) {
 			case *ast.Ident:
@@ -307,9 +365,17 @@ func emitFieldAssignments(b *strings.Builder, outVar string, prefix string, fiel
 			b.WriteString(fmt.Sprintf("\tif s := vals.Get(%q); s != \"\" {\n", key))
 			switch {
 			case f.IsTime:
-				b.WriteString("\t\tif tt, ok := _db_parseTimeFlexible(s); ok { ")
+				layoutArg := ""
+				if f.Layout != "" {
+					layoutArg = fmt.Sprintf(", %q", f.Layout)
+				}
+				b.WriteString(fmt.Sprintf("\t\tif tt, ok := %sParseTime(s, nil%s); ok { ", runtimeQual, layoutArg))
 				b.WriteString(fmt.Sprintf("%s.%s = tt", outVar, f.Name))
 				b.WriteString(" }\n")
+			case f.IsDur:
+				b.WriteString(fmt.Sprintf("\t\tif d, ok := %sParseDuration(s); ok { ", runtimeQual))
+				b.WriteString(fmt.Sprintf("%s.%s = d", outVar, f.Name))
+				b.WriteString(" }\n")
 			case f.TypeExpr == "string":
 				b.WriteString(fmt.Sprintf("\t\t%s.%s = s\n", outVar, f.Name))
 			case f.TypeExpr == "bool":
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"go/ast"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	databridge "github.com/dataBridgeGoPkg/dataBridge"
 )
 
 func TestSplitCSV(t *testing.T) {
@@ -80,3 +85,50 @@ func TestCollectFieldsAndEmit(t *testing.T) {
 		t.Fatalf("missing nested dotted key for address.city in: %s", src)
 	}
 }
+
+func TestTimeAndDurationUseRuntimeParsers(t *testing.T) {
+	fl := &ast.FieldList{List: []*ast.Field{
+		{Names: []*ast.Ident{{Name: "At"}}, Type: &ast.SelectorExpr{X: &ast.Ident{Name: "time"}, Sel: &ast.Ident{Name: "Time"}}, Tag: &ast.BasicLit{Kind: 1, Value: "`json:\"at\" databridge:\"layout='Jan 2, 2006'\"`"}},
+		{Names: []*ast.Ident{{Name: "TTL"}}, Type: &ast.SelectorExpr{X: &ast.Ident{Name: "time"}, Sel: &ast.Ident{Name: "Duration"}}, Tag: &ast.BasicLit{Kind: 1, Value: "`json:\"ttl\"`"}},
+	}}
+	fields := collectFields(fl, "")
+	if len(fields) != 2 || fields[0].Layout != "Jan 2, 2006" || !fields[1].IsDur {
+		t.Fatalf("unexpected fields: %+v", fields)
+	}
+	var b strings.Builder
+	emitFieldAssignments(&b, "out", "", fields)
+	src := b.String()
+	if !strings.Contains(src, `databridge.ParseTime(s, nil, "Jan 2, 2006")`) {
+		t.Fatalf("missing runtime ParseTime call in: %s", src)
+	}
+	if !strings.Contains(src, "databridge.ParseDuration(s)") {
+		t.Fatalf("missing runtime ParseDuration call in: %s", src)
+	}
+}
+
+func TestSchemaMatchesRuntime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs a program")
+	}
+	pkg, err := loadPackage("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := writeSchemas(pkg.Types, "../..", []string{"User", "Order"}, dir); err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]*databridge.Schema{"User": databridge.SchemaFor[databridge.User](), "Order": databridge.SchemaFor[databridge.Order]()} {
+		got, err := os.ReadFile(filepath.Join(dir, name+".schema.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := json.MarshalIndent(s, "", "  ")
+		if string(got) != string(want)+"\n" {
+			t.Fatalf("generated %s schema differs from SchemaFor:\n%s\nwant:\n%s", name, got, want)
+		}
+	}
+	if err := writeSchemas(pkg.Types, "../..", []string{"Missing"}, dir); err == nil {
+		t.Fatal("expected an error for an unknown type")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+)
+
+// schemaProgram calls databridge.SchemaFor for each type, so the files match
+// the runtime exactly, converters registered by the package's init included.
+var schemaProgram = template.Must(template.New("schema").Parse(`// Code generated by databridge-gen; DO NOT EDIT.
+
+package main
+
+import (
+	"log"
+
+	databridge "{{.Runtime}}"
+	target "{{.Pkg}}"
+)
+
+func main() {
+{{- range .Types}}
+	if err := databridge.WriteSchemaFile({{printf "%q" $.Dir}}, {{printf "%q" .}}, databridge.SchemaFor[target.{{.}}]()); err != nil {
+		log.Fatal(err)
+	}
+{{- end}}
+}
+`))
+
+// writeSchemas writes the JSON Schema of each named type of pkg, whose sources
+// are in pkgDir, to dir/<Type>.schema.json. It builds and runs a throwaway
+// program next to the package, so the package must be importable.
+func writeSchemas(pkg *types.Package, pkgDir string, typeNames []string, dir string) error {
+	if pkg.Name() == "main" {
+		return fmt.Errorf("cannot write schemas for package main: it cannot be imported")
+	}
+	for _, name := range typeNames {
+		if _, ok := pkg.Scope().Lookup(name).(*types.TypeName); !ok {
+			return fmt.Errorf("type %s not found in %s", name, pkg.Path())
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	var src bytes.Buffer
+	err = schemaProgram.Execute(&src, struct {
+		Runtime, Pkg, Dir string
+		Types             []string
+	}{runtimePkgPath, pkg.Path(), dir, typeNames})
+	if err != nil {
+		return err
+	}
+	code, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// a directory starting with "." is skipped by ./... patterns and by go vet
+	tmp, err := os.MkdirTemp(pkgDir, ".databridge-schema-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.WriteFile(filepath.Join(tmp, "main.go"), code, 0644); err != nil {
+		return err
+	}
+	cmd := exec.Command("go", "run", "./"+filepath.Base(tmp))
+	cmd.Dir = pkgDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("run schema program: %v\n%s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+// Command databridge-infer writes Go struct definitions for sample payloads.
+// It reads one or more files in any format databridge detects (JSON, CSV, form
+// encoding, XML, or YAML for .yaml/.yml files and with -yaml), merges the
+// shapes of all their records and infers field types: int64, float64, bool,
+// time.Time (strings databridge.ParseTime accepts), string for mixed scalars,
+// nested structs, slices, and pointers for fields that are missing or null in
+// some records. The output is gofmt'd Go with json tags, ready for
+// databridge.Transform[T].
+//
+// Usage:
+//
+//	go run ./cmd/databridge-infer -type Order -pkg orders samples/*.json > order.go
+//	go run ./cmd/databridge-infer -type Row -root data.items -out row.go feed.json
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	databridge "github.com/dataBridgeGoPkg/dataBridge"
+)
+
+func main() {
+	var (
+		typeName string
+		pkgName  string
+		out      string
+		root     string
+		yaml     bool
+	)
+	flag.StringVar(&typeName, "type", "Record", "name of the top-level struct type")
+	flag.StringVar(&pkgName, "pkg", "main", "package name of the generated file")
+	flag.StringVar(&out, "out", "", "output file (default: standard output)")
+	flag.StringVar(&root, "root", "", "path of the records inside each sample, as for databridge.WithRoot")
+	flag.BoolVar(&yaml, "yaml", false, "also detect YAML in files without a .yaml or .yml extension")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: databridge-infer [flags] sample...")
+	}
+
+	src, err := infer(flag.Args(), options{typeName: typeName, pkgName: pkgName, root: root, yaml: yaml})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(out, src, 0644); err != nil {
+		log.Fatalf("write error: %v", err)
+	}
+}
+
+type options struct {
+	typeName string
+	pkgName  string
+	root     string
+	yaml     bool
+}
+
+// infer reads the sample files and returns the Go source for their records.
+func infer(files []string, o options) ([]byte, error) {
+	top := &node{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var opts []databridge.Option
+		if ext := strings.ToLower(filepath.Ext(f)); o.yaml || ext == ".yaml" || ext == ".yml" {
+			opts = append(opts, databridge.WithYAML(true))
+		}
+		if o.root != "" {
+			opts = append(opts, databridge.WithRoot(o.root))
+		}
+		records, _, err := databridge.ParseRecords(b, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		for _, r := range records {
+			top.add(r)
+		}
+	}
+
+	g := &emitter{names: map[string]bool{}}
+	g.structType(o.typeName, top)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by databridge-infer from %s; edit as needed.\n\n", strings.Join(baseNames(files), ", "))
+	fmt.Fprintf(&b, "package %s\n\n", o.pkgName)
+	if g.usesTime {
+		b.WriteString("import \"time\"\n\n")
+	}
+	for _, d := range g.decls {
+		b.WriteString(d)
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format error: %v\nsource:\n%s", err, b.Bytes())
+	}
+	return src, nil
+}
+
+func baseNames(files []string) []string {
+	out := make([]string, len(files))
+	for i, f := range files {
+		out[i] = filepath.Base(f)
+	}
+	return out
+}
+
+// kind bits of the values observed at a position
+const (
+	kBool = 1 << iota
+	kInt
+	kUint
+	kFloat
+	kString
+	kTime
+	kObject
+	kArray
+)
+
+// node accumulates the values observed at one position of the samples.
+type node struct {
+	kinds   int
+	present int              // values seen here, nulls included
+	null    bool             // a null or empty string was seen
+	objects int              // objects seen here
+	fields  map[string]*node // keys of those objects
+	elem    *node            // elements of arrays seen here
+}
+
+func (n *node) add(v interface{}) {
+	n.present++
+	switch x := v.(type) {
+	case nil:
+		n.null = true
+	case string:
+		if strings.TrimSpace(x) == "" {
+			n.null = true // empty strings leave pointers nil, like nulls
+			break
+		}
+		n.kinds |= stringKind(x)
+	case bool:
+		n.kinds |= kBool
+	case int64, int:
+		n.kinds |= kInt
+	case uint64:
+		n.kinds |= kUint
+	case float64:
+		n.kinds |= kFloat
+	case json.Number:
+		n.kinds |= numberKind(x)
+	case time.Time:
+		n.kinds |= kTime
+	case map[string]interface{}:
+		n.kinds |= kObject
+		n.objects++
+		if n.fields == nil {
+			n.fields = map[string]*node{}
+		}
+		for k, fv := range x {
+			f := n.fields[k]
+			if f == nil {
+				f = &node{}
+				n.fields[k] = f
+			}
+			f.add(fv)
+		}
+	case []interface{}:
+		n.kinds |= kArray
+		if n.elem == nil {
+			n.elem = &node{}
+		}
+		for _, e := range x {
+			n.elem.add(e)
+		}
+	default:
+		n.kinds |= kString
+	}
+}
+
+// numberKind classifies a number literal as int, uint or float.
+func numberKind(n json.Number) int {
+	if _, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		return kInt
+	}
+	if _, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return kUint
+	}
+	return kFloat
+}
+
+// stringKind classifies a string as a time (as Transform would parse it into a
+// time.Time field, numbers aside) or plain text.
+func stringKind(s string) int {
+	s = strings.TrimSpace(s)
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		if _, ok := databridge.ParseTime(s, nil); ok {
+			return kTime
+		}
+	}
+	return kString
+}
+
+// emitter renders the struct declarations of a node tree.
+type emitter struct {
+	decls    []string
+	names    map[string]bool // type names in use
+	usesTime bool
+}
+
+// structType declares a struct type for the objects of n and returns its name.
+func (g *emitter) structType(name string, n *node) string {
+	name = uniqueIdent(name, g.names)
+	idx := len(g.decls)
+	g.decls = append(g.decls, "") // keep parents before their nested types
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	keys := make([]string, 0, len(n.fields))
+	for k := range n.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	used := map[string]bool{}
+	for _, k := range keys {
+		f := n.fields[k]
+		field := uniqueIdent(goName(k), used)
+		optional := f.present < n.objects || f.null
+		typ := g.goType(name+field, f)
+		if optional && pointerable(typ) {
+			typ = "*" + typ
+		}
+		tag := ""
+		if !strings.ContainsAny(k, "\",`\\") {
+			opt := ""
+			if optional {
+				opt = ",omitempty"
+			}
+			tag = fmt.Sprintf(" `json:\"%s%s\"`", k, opt)
+		}
+		fmt.Fprintf(&b, "\t%s %s%s\n", field, typ, tag)
+	}
+	b.WriteString("}\n\n")
+	g.decls[idx] = b.String()
+	return name
+}
+
+// goType returns the Go type of the values of n; name is used for struct types.
+func (g *emitter) goType(name string, n *node) string {
+	switch k := n.kinds; {
+	case k == 0:
+		return "interface{}"
+	case k == kObject:
+		return g.structType(name, n)
+	case k == kArray:
+		if n.elem.kinds == 0 {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(singular(name), n.elem)
+	case k&(kObject|kArray) != 0:
+		return "interface{}" // objects or arrays mixed with other values
+	case k == kBool:
+		return "bool"
+	case k == kInt:
+		return "int64"
+	case k == kUint:
+		return "uint64"
+	case k&^(kInt|kUint|kFloat) == 0:
+		return "float64"
+	case k == kTime:
+		g.usesTime = true
+		return "time.Time"
+	}
+	return "string" // mixed scalars; databridge converts numbers and booleans to strings
+}
+
+func pointerable(typ string) bool {
+	return !strings.HasPrefix(typ, "[]") && typ != "interface{}"
+}
+
+// uniqueIdent returns name, or name with a number appended if used already has
+// it, and marks the result used.
+func uniqueIdent(name string, used map[string]bool) string {
+	out := name
+	for i := 2; used[out]; i++ {
+		out = fmt.Sprintf("%s%d", name, i)
+	}
+	used[out] = true
+	return out
+}
+
+// commonInitialisms are written in upper case in Go names, as golint suggests.
+var commonInitialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "GUID": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "QPS": true,
+	"RAM": true, "RPC": true, "SKU": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true,
+	"TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true,
+	"URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+}
+
+// goName converts an input key such as "first_name", "orderId" or "Zip-Code"
+// into an exported Go identifier.
+func goName(key string) string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = nil
+		}
+	}
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+	var b strings.Builder
+	for _, w := range words {
+		if up := strings.ToUpper(w); commonInitialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		rs := []rune(strings.ToLower(w))
+		rs[0] = unicode.ToUpper(rs[0])
+		b.WriteString(string(rs))
+	}
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+	if r := []rune(name)[0]; !unicode.IsLetter(r) {
+		name = "F" + name
+	}
+	return name
+}
+
+// singular names the element type of a slice type named name.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"), strings.HasSuffix(name, "xes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name + "Item"
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"first_name": "FirstName",
+		"orderId":    "OrderID",
+		"Zip-Code":   "ZipCode",
+		"HTTPStatus": "HTTPStatus",
+		"url":        "URL",
+		"2fa":        "F2fa",
+		"$":          "Field",
+	} {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSingular(t *testing.T) {
+	for in, want := range map[string]string{
+		"OrderItems":     "OrderItem",
+		"OrderEntries":   "OrderEntry",
+		"OrderAddresses": "OrderAddress",
+		"OrderData":      "OrderDataItem",
+	} {
+		if got := singular(in); got != want {
+			t.Errorf("singular(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func writeSample(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestInfer(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		writeSample(t, dir, "a.json", `[
+			{"id": 1, "total": 9.5, "paid": true, "created_at": "2024-03-01T10:00:00Z", "code": "7",
+			 "customer": {"name": "Ada", "email": "ada@example.com"},
+			 "items": [{"sku": "X1", "qty": 2}], "note": null},
+			{"id": 2, "total": 10, "paid": false, "created_at": "2024-03-02", "code": 8,
+			 "customer": {"name": "Bob"}, "items": [], "first,name": "x"}
+		]`),
+		writeSample(t, dir, "b.csv", "id,total,paid,created_at,code,discount\n3,11,true,2024-03-03,9,\n"),
+		writeSample(t, dir, "c.yaml", "id: 4\ntotal: 1.5\npaid: true\ncreated_at: 2024-03-04\ncode: x\n"),
+	}
+	src, err := infer(files, options{typeName: "Order", pkgName: "orders"})
+	if err != nil {
+		t.Fatalf("infer: %v", err)
+	}
+	out := string(src)
+	for _, want := range []string{
+		"// Code generated by databridge-infer from a.json, b.csv, c.yaml; edit as needed.",
+		"package orders",
+		`import "time"`,
+		"ID int64 `json:\"id\"`",
+		"Total float64 `json:\"total\"`",
+		"Paid bool `json:\"paid\"`",
+		"CreatedAt time.Time `json:\"created_at\"`",
+		"Code string `json:\"code\"`",
+		"Customer *OrderCustomer `json:\"customer,omitempty\"`",
+		"Discount interface{} `json:\"discount,omitempty\"`",
+		"Items []OrderItem `json:\"items,omitempty\"`",
+		"FirstName *string\n",
+		"Email *string `json:\"email,omitempty\"`",
+		"type OrderItem struct {",
+		"Qty int64 `json:\"qty\"`",
+	} {
+		if !strings.Contains(squash(out), want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "order.go", src, 0); err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, out)
+	}
+	if formatted, _ := format.Source(src); string(formatted) != out {
+		t.Fatalf("generated source is not gofmt'd:\n%s", out)
+	}
+}
+
+func TestInferRoot(t *testing.T) {
+	dir := t.TempDir()
+	f := writeSample(t, dir, "feed.json", `{"data":{"items":[{"sku":"A","price":1.5},{"sku":"B"}]}}`)
+	src, err := infer([]string{f}, options{typeName: "Row", pkgName: "main", root: "data.items"})
+	if err != nil {
+		t.Fatalf("infer: %v", err)
+	}
+	if out := squash(string(src)); strings.Contains(out, "time") || !strings.Contains(out, "Price *float64 `json:\"price,omitempty\"`") ||
+		!strings.Contains(out, "SKU string `json:\"sku\"`") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	if _, err := infer([]string{filepath.Join(dir, "missing.json")}, options{typeName: "Row", pkgName: "main"}); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
+
+func TestInferNestedNumbers(t *testing.T) {
+	dir := t.TempDir()
+	f := writeSample(t, dir, "grid.json", `{"grid":[[1,2],[3]],"big":[[18446744073709551615]],"ratio":[[0.5],[1]]}`)
+	src, err := infer([]string{f}, options{typeName: "Grid", pkgName: "main"})
+	if err != nil {
+		t.Fatalf("infer: %v", err)
+	}
+	out := squash(string(src))
+	for _, want := range []string{"Grid [][]int64 `json:\"grid\"`", "Big [][]uint64 `json:\"big\"`", "Ratio [][]float64 `json:\"ratio\"`"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %s in:\n%s", want, out)
+		}
+	}
+
+	// json.Number values, as from a map input, are numbers too
+	n := &node{}
+	n.add(json.Number("7"))
+	n.add(json.Number("7.5"))
+	if n.kinds != kInt|kFloat {
+		t.Fatalf("kinds = %b", n.kinds)
+	}
+}
+
+// squash collapses runs of blanks, so checks do not depend on gofmt alignment.
+func squash(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.Join(strings.Fields(l), " ")
+	}
+	return strings.Join(lines, "\n")
+}
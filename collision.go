@@ -0,0 +1,89 @@
+package databridge
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+var ErrKeyCollision = errors.New("databridge: ambiguous keys")
+
+// KeyCollision describes keys that competed for the same target. For input keys,
+// Sources are the colliding input keys and Winner the one that was used; for
+// struct declarations, Sources are the Go names of fields that normalize to the
+// same key and Winner the field that claims it.
+type KeyCollision struct {
+	Type    string   // struct type being matched
+	Field   string   // JSON name of the contested field; empty for unknown keys
+	Sources []string // competing keys or fields, sorted
+	Winner  string
+}
+
+func (c KeyCollision) String() string {
+	target := "unknown key"
+	if c.Field != "" {
+		target = fmt.Sprintf("field %q", c.Field)
+	}
+	return fmt.Sprintf("%s %s: %s collide, %q wins", c.Type, target, strings.Join(quoteAll(c.Sources), ", "), c.Winner)
+}
+
+// KeyCollisionError is returned when WithCollisionError is set and collisions
+// were found. It wraps ErrKeyCollision.
+type KeyCollisionError struct {
+	Collisions []KeyCollision
+}
+
+func (e *KeyCollisionError) Error() string {
+	parts := make([]string, len(e.Collisions))
+	for i, c := range e.Collisions {
+		parts[i] = c.String()
+	}
+	return fmt.Sprintf("%v: %s", ErrKeyCollision, strings.Join(parts, "; "))
+}
+
+func (e *KeyCollisionError) Unwrap() error { return ErrKeyCollision }
+
+// WithCollisionError makes a transform fail with a *KeyCollisionError when input
+// keys or struct fields collide after normalization, instead of resolving them by
+// precedence (exact JSON name, then normalized JSON name, then Go field name;
+// the smallest key on ties). Collisions are always reported to the Logger.
+func WithCollisionError(enabled bool) Option {
+	return func(c *config) { c.CollisionError = enabled }
+}
+
+// recordCollision notes a collision once per call and reports it to the Logger.
+func (c *config) recordCollision(kc KeyCollision) {
+	kc.Sources = append([]string(nil), kc.Sources...)
+	sort.Strings(kc.Sources)
+	id := kc.String()
+	if c.collisionSeen[id] {
+		return
+	}
+	if c.collisionSeen == nil {
+		c.collisionSeen = map[string]bool{}
+	}
+	c.collisionSeen[id] = true
+	c.collisions = append(c.collisions, kc)
+	c.Logger("key collision: %s", id)
+	c.logEvent(slog.LevelWarn, "key collision", slog.String("type", kc.Type), slog.String("field", kc.Field),
+		slog.Any("sources", kc.Sources), slog.String("winner", kc.Winner))
+}
+
+// collisionError returns the collisions found so far as an error when
+// WithCollisionError is set.
+func (c *config) collisionError() error {
+	if !c.CollisionError || len(c.collisions) == 0 {
+		return nil
+	}
+	return &KeyCollisionError{Collisions: c.collisions}
+}
+
+func quoteAll(ss []string) []string {
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = fmt.Sprintf("%q", s)
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package databridge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestKeyCollisionPrecedence(t *testing.T) {
+	type Account struct {
+		UserName string `json:"user_name"`
+	}
+	// exact JSON name beats normalized JSON name beats Go field name, whatever
+	// the map iteration order
+	for i := 0; i < 20; i++ {
+		var a Account
+		if err := TransformToStructUniversal(`{"UserName":"go","USER-NAME":"norm","user_name":"exact"}`, &a); err != nil || a.UserName != "exact" {
+			t.Fatalf("exact precedence = %+v err=%v", a, err)
+		}
+		a = Account{}
+		if err := TransformToStructUniversal(`{"UserName":"go","USER-NAME":"norm"}`, &a); err != nil || a.UserName != "norm" {
+			t.Fatalf("normalized tag precedence = %+v err=%v", a, err)
+		}
+	}
+}
+
+func TestKeyCollisionReportAndError(t *testing.T) {
+	type Dup struct {
+		UserName string `json:"username"`
+		Login    string `json:"user_name"`
+	}
+	var logs []string
+	logger := func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }
+	var d Dup
+	if err := TransformToStructUniversal(`{"username":"a"}`, &d, WithLogger(logger)); err != nil {
+		t.Fatalf("transform failed: %v", err)
+	}
+	if d.UserName != "a" || d.Login != "" {
+		t.Fatalf("first declared field should win: %+v", d)
+	}
+	if len(logs) != 1 || !strings.Contains(logs[0], `"Login", "UserName" collide, "UserName" wins`) {
+		t.Fatalf("collision log = %q", logs)
+	}
+
+	type Account struct {
+		Name string `json:"name"`
+	}
+	var a Account
+	err := TransformToStructUniversal(`{"Name":"a","NAME":"b","x-y":1,"xy":2}`, &a, WithCollisionError(true))
+	var ce *KeyCollisionError
+	if !errors.As(err, &ce) || !errors.Is(err, ErrKeyCollision) || len(ce.Collisions) != 2 {
+		t.Fatalf("expected two collisions, got %v", err)
+	}
+	for _, c := range ce.Collisions {
+		switch c.Field {
+		case "name":
+			if c.Winner != "NAME" || strings.Join(c.Sources, ",") != "NAME,Name" {
+				t.Fatalf("field collision = %+v", c)
+			}
+		case "":
+			if c.Winner != "xy" {
+				t.Fatalf("unknown key collision = %+v", c)
+			}
+		}
+	}
+}
+
+func TestKeyCollisionReportOrder(t *testing.T) {
+	type Wide struct {
+		A string `json:"a"`
+		B string `json:"b"`
+		C string `json:"c"`
+		D string `json:"d"`
+	}
+	in := `{"A":1,"a_":2,"B":1,"b_":2,"C":1,"c_":2,"D":1,"d_":2,"X":1,"x_":2,"Y":1,"y_":2}`
+	var first string
+	for i := 0; i < 20; i++ {
+		var logs []string
+		logger := func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }
+		var w Wide
+		err := TransformToStructUniversal(in, &w, WithLogger(logger), WithCollisionError(true))
+		var ce *KeyCollisionError
+		if !errors.As(err, &ce) || len(ce.Collisions) != 6 {
+			t.Fatalf("expected six collisions, got %v", err)
+		}
+		got := err.Error() + "\n" + strings.Join(logs, "\n")
+		if i == 0 {
+			first = got
+			if ce.Collisions[0].Field != "a" || ce.Collisions[3].Field != "d" || ce.Collisions[4].Sources[0] != "X" {
+				t.Fatalf("collisions not in key order: %v", ce.Collisions)
+			}
+		} else if got != first {
+			t.Fatalf("collision report changed between runs:\n%s\nvs\n%s", got, first)
+		}
+	}
+}
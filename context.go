@@ -0,0 +1,113 @@
+package databridge
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// CanceledError is returned when the context of TransformContext or
+// TransformSeqContext is done. It wraps ctx.Err(), so errors.Is(err,
+// context.Canceled) and errors.Is(err, context.DeadlineExceeded) work.
+type CanceledError struct {
+	Phase string // "read", "parse", "map" or "decode"
+	Rows  int    // rows parsed (parse phase) or mapped (map and decode phases) so far
+	Err   error
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("databridge: stopped in %s phase after %d rows: %v", e.Phase, e.Rows, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error { return e.Err }
+
+// checkContext marks the start of phase (repeated calls for the same phase, e.g.
+// per row, only check the context) and returns a *CanceledError when the call's
+// context is done.
+func (c *config) checkContext(phase string, rows int) error {
+	c.enterPhase(phase)
+	if err := c.ctx.Err(); err != nil {
+		return &CanceledError{Phase: phase, Rows: rows, Err: err}
+	}
+	return nil
+}
+
+// TransformSeqContext decodes the records of input (CSV rows, the objects of a JSON
+// array, or a single object) one at a time into values of type T. A record that
+// fails to decode yields its error and iteration continues with the next; a done
+// ctx yields a *CanceledError and ends the sequence, as does an input that cannot
+// be parsed. Merge mode does not apply.
+//
+// Example:
+//
+//	for row, err := range databridge.TransformSeqContext[Order](ctx, r.Body) {
+//		if err != nil { /* log and skip, or break */ }
+//		save(row)
+//	}
+func TransformSeqContext[T any](ctx context.Context, input interface{}, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cfg := newConfig(opts)
+		cfg.ctx = ctx
+		cfg.observeStart()
+		var (
+			m    map[string]interface{}
+			rows []map[string]interface{}
+			keep bool
+			err  error // ends the sequence
+		)
+		defer func() { cfg.observeDone(err) }()
+		if err = cfg.checkContext("parse", 0); err == nil {
+			m, rows, err = parseInput(input, cfg)
+		}
+		if err == nil {
+			m, rows, keep, err = cfg.reshape(m, rows)
+		}
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		if !keep {
+			return
+		}
+		if rows == nil {
+			rows = []map[string]interface{}{m}
+		}
+		typ := reflect.TypeOf((*T)(nil)).Elem()
+		for i, row := range rows {
+			if err = cfg.checkContext("map", i); err != nil {
+				yield(zero, err)
+				return
+			}
+			// records are independent: report each record's own collisions
+			cfg.collisions, cfg.collisionSeen = nil, nil
+			var out T
+			path := fmt.Sprintf("[%d]", i)
+			rerr := decodeSeqRecord(cfg, row, typ, path, &out)
+			cfg.rowDone(i, rerr)
+			if rerr != nil {
+				if !yield(zero, rerr) {
+					return
+				}
+				continue
+			}
+			if !yield(out, nil) {
+				return
+			}
+		}
+	}
+}
+
+// decodeSeqRecord prepares and decodes one record into out and runs its AfterBind
+// hooks.
+func decodeSeqRecord(cfg *config, row map[string]interface{}, typ reflect.Type, path string, out interface{}) error {
+	mapped, err := cfg.prepareRecord(row, typ, path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.decodeRecord(mapped, out); err != nil {
+		return err
+	}
+	return runAfterBind(cfg.ctx, reflect.ValueOf(out).Elem(), path)
+}
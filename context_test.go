@@ -0,0 +1,122 @@
+package databridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type ctxKey struct{}
+
+type ctxHooked struct {
+	Name   string `json:"name"`
+	Before string `json:"before"`
+	After  string `json:"-"`
+}
+
+func (h *ctxHooked) BeforeBindContext(ctx context.Context, raw map[string]interface{}) error {
+	raw["before"] = ctx.Value(ctxKey{})
+	return nil
+}
+
+func (h *ctxHooked) AfterBindContext(ctx context.Context) error {
+	h.After = fmt.Sprint(ctx.Value(ctxKey{}))
+	return nil
+}
+
+func TestTransformContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "req-1")
+	var h ctxHooked
+	if err := TransformContext(ctx, `{"name":"x"}`, &h); err != nil {
+		t.Fatalf("TransformContext: %v", err)
+	}
+	if h.Before != "req-1" || h.After != "req-1" {
+		t.Fatalf("hooks did not see ctx: %+v", h)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := TransformContext(canceled, `{"name":"x"}`, &h)
+	var ce *CanceledError
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &ce) || ce.Phase != "read" {
+		t.Fatalf("err = %v", err)
+	}
+
+	// a converter cancels while rows are mapped; the converter sees the ctx value
+	type Row struct {
+		Code testMoney `json:"code"`
+	}
+	rowCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	calls := 0
+	conv := WithConverterContext(func(ctx context.Context, v any) (testMoney, error) {
+		if ctx.Value(ctxKey{}) != "req-1" {
+			return testMoney{}, fmt.Errorf("converter lacks ctx")
+		}
+		if calls++; calls == 3 {
+			stop()
+		}
+		return testMoney{Cents: 1}, nil
+	})
+	var rows []Row
+	err = TransformContext(rowCtx, "code,x\n1,a\n2,b\n3,c\n4,d\n5,e\n", &rows, conv)
+	if !errors.As(err, &ce) || ce.Phase != "map" || ce.Rows != 3 || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v", err)
+	}
+	if !strings.Contains(err.Error(), "after 3 rows") {
+		t.Fatalf("error lacks progress: %v", err)
+	}
+}
+
+func TestTransformSeqContext(t *testing.T) {
+	type Row struct {
+		Name string `json:"name"`
+		Age  int8   `json:"age"`
+	}
+	in := "name,age\nada,36\nbob,300\ncy,41\n"
+	var names []string
+	var errs []error
+	for r, err := range TransformSeqContext[Row](context.Background(), in) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		names = append(names, r.Name)
+	}
+	var fe *FieldError
+	if strings.Join(names, ",") != "ada,cy" || len(errs) != 1 || !errors.As(errs[0], &fe) || fe.Path != "[1].age" {
+		t.Fatalf("names=%v errs=%v", names, errs)
+	}
+
+	// a single object yields once
+	n := 0
+	for r, err := range TransformSeqContext[Row](context.Background(), `{"Name":"solo"}`) {
+		if err != nil || r.Name != "solo" {
+			t.Fatalf("single object: %+v, %v", r, err)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("single object yielded %d times", n)
+	}
+
+	// cancellation ends the sequence with a CanceledError
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n = 0
+	var last error
+	for _, err := range TransformSeqContext[Row](ctx, in) {
+		if err != nil {
+			last = err
+			continue
+		}
+		n++
+		cancel()
+	}
+	var ce *CanceledError
+	if n != 1 || !errors.As(last, &ce) || ce.Rows != 1 {
+		t.Fatalf("n=%d last=%v", n, last)
+	}
+}
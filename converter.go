@@ -0,0 +1,182 @@
+package databridge
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// converterFunc converts a raw decoded value (string, int64, float64, bool,
+// map[string]interface{} or []interface{}) into a registered target type. ctx is
+// the context of the transform.
+type converterFunc func(ctx context.Context, v interface{}) (interface{}, error)
+
+// converterSet holds converters keyed by target type and by name (for `databridge:"conv=name"`).
+type converterSet struct {
+	byType map[reflect.Type]converterFunc
+	byName map[string]converterFunc
+}
+
+func (s *converterSet) addType(t reflect.Type, fn converterFunc) {
+	if s.byType == nil {
+		s.byType = map[reflect.Type]converterFunc{}
+	}
+	s.byType[t] = fn
+}
+
+func (s *converterSet) addName(name string, fn converterFunc) {
+	if s.byName == nil {
+		s.byName = map[string]converterFunc{}
+	}
+	s.byName[name] = fn
+}
+
+var (
+	globalConvertersMu sync.RWMutex
+	globalConverters   converterSet
+)
+
+// wrapConverter adapts a typed converter to the untyped form stored in a converterSet.
+func wrapConverter[T any](fn func(v any) (T, error)) converterFunc {
+	return func(_ context.Context, v interface{}) (interface{}, error) {
+		return fn(v)
+	}
+}
+
+// wrapConverterContext adapts a typed context-aware converter.
+func wrapConverterContext[T any](fn func(ctx context.Context, v any) (T, error)) converterFunc {
+	return func(ctx context.Context, v interface{}) (interface{}, error) {
+		return fn(ctx, v)
+	}
+}
+
+// RegisterConverter registers fn as the process-wide converter for target type T.
+// It is consulted before the built-in kind-based coercion whenever a field of
+// type T (or *T, or a slice element of type T) receives a non-nil value, whatever
+// the input format. The returned value is encoded with encoding/json on its way
+// into the output, so T must round-trip through JSON.
+//
+// Example:
+//
+//	databridge.RegisterConverter(func(v any) (Money, error) { return ParseMoney(fmt.Sprint(v)) })
+func RegisterConverter[T any](fn func(v any) (T, error)) {
+	globalConvertersMu.Lock()
+	defer globalConvertersMu.Unlock()
+	globalConverters.addType(reflect.TypeOf((*T)(nil)).Elem(), wrapConverter(fn))
+}
+
+// RegisterNamedConverter registers fn process-wide under name. Fields tagged
+// `databridge:"conv=name"` use it regardless of their type.
+func RegisterNamedConverter[T any](name string, fn func(v any) (T, error)) {
+	globalConvertersMu.Lock()
+	defer globalConvertersMu.Unlock()
+	globalConverters.addName(name, wrapConverter(fn))
+}
+
+// WithConverter registers fn as the converter for target type T for a single call
+// (or for every call of a Bridge). It takes precedence over RegisterConverter.
+func WithConverter[T any](fn func(v any) (T, error)) Option {
+	return func(c *config) { c.converters.addType(reflect.TypeOf((*T)(nil)).Elem(), wrapConverter(fn)) }
+}
+
+// WithNamedConverter registers fn under name for a single call (or Bridge).
+// It takes precedence over RegisterNamedConverter.
+func WithNamedConverter[T any](name string, fn func(v any) (T, error)) Option {
+	return func(c *config) { c.converters.addName(name, wrapConverter(fn)) }
+}
+
+// WithConverterContext is WithConverter for converters that need the context
+// passed to TransformContext, e.g. to look values up in a database.
+func WithConverterContext[T any](fn func(ctx context.Context, v any) (T, error)) Option {
+	return func(c *config) { c.converters.addType(reflect.TypeOf((*T)(nil)).Elem(), wrapConverterContext(fn)) }
+}
+
+// WithNamedConverterContext is WithNamedConverter for context-aware converters.
+func WithNamedConverterContext[T any](name string, fn func(ctx context.Context, v any) (T, error)) Option {
+	return func(c *config) { c.converters.addName(name, wrapConverterContext(fn)) }
+}
+
+// lookupConverter finds a converter by name (when set) or by type, per-call first.
+func lookupConverter(t reflect.Type, name string, cfg *config) (converterFunc, bool) {
+	if name != "" {
+		if fn, ok := cfg.converters.byName[name]; ok {
+			return fn, true
+		}
+		globalConvertersMu.RLock()
+		defer globalConvertersMu.RUnlock()
+		fn, ok := globalConverters.byName[name]
+		return fn, ok
+	}
+	if fn, ok := cfg.converters.byType[t]; ok {
+		return fn, true
+	}
+	globalConvertersMu.RLock()
+	defer globalConvertersMu.RUnlock()
+	fn, ok := globalConverters.byType[t]
+	return fn, ok
+}
+
+// hasTypeConverter reports whether a per-call or registered converter applies to
+// t or a type reachable through its fields, elements or pointers. Unlike
+// typeHas it is not cached, as converters can be registered at any time.
+func (c *config) hasTypeConverter(t reflect.Type) bool {
+	globalConvertersMu.RLock()
+	global := len(globalConverters.byType)
+	globalConvertersMu.RUnlock()
+	if global == 0 && len(c.converters.byType) == 0 {
+		return false
+	}
+	return c.hasTypeConverterWalk(t, map[reflect.Type]bool{})
+}
+
+func (c *config) hasTypeConverterWalk(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if _, ok := lookupConverter(t, "", c); ok {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return c.hasTypeConverterWalk(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if sf := t.Field(i); sf.PkgPath == "" && c.hasTypeConverterWalk(sf.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// convertWithRegistered applies a registered converter for the target type t (or its
+// pointer element type) or the converter named in the field tag.
+// handled reports whether a converter was applied (or was named but is missing).
+func convertWithRegistered(v interface{}, t reflect.Type, tag fieldTag, cfg *config) (out interface{}, handled bool, err error) {
+	if v == nil {
+		return nil, false, nil
+	}
+	if tag.Conv != "" {
+		// a named converter on a slice field applies to each element
+		if _, isArr := v.([]interface{}); isArr && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			return nil, false, nil
+		}
+		fn, ok := lookupConverter(t, tag.Conv, cfg)
+		if !ok {
+			return nil, true, fmt.Errorf("unknown converter %q", tag.Conv)
+		}
+		out, err = fn(cfg.ctx, v)
+		return out, true, err
+	}
+	fn, ok := lookupConverter(t, "", cfg)
+	if !ok && t.Kind() == reflect.Ptr {
+		fn, ok = lookupConverter(t.Elem(), "", cfg)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	out, err = fn(cfg.ctx, v)
+	return out, true, err
+}
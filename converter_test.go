@@ -0,0 +1,147 @@
+package databridge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testMoney struct {
+	Cents    int64  `json:"cents"`
+	Currency string `json:"currency"`
+}
+
+func parseTestMoney(v any) (testMoney, error) {
+	s, ok := v.(string)
+	if !ok {
+		return testMoney{}, fmt.Errorf("want string, got %T", v)
+	}
+	amount, cur, found := strings.Cut(strings.TrimSpace(s), " ")
+	if !found {
+		return testMoney{}, fmt.Errorf("missing currency in %q", s)
+	}
+	units, frac, _ := strings.Cut(amount, ",")
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return testMoney{}, err
+	}
+	f, _ := strconv.ParseInt(frac, 10, 64)
+	return testMoney{Cents: u*100 + f, Currency: cur}, nil
+}
+
+func TestConverterPerCallAllFormats(t *testing.T) {
+	type Invoice struct {
+		Total testMoney   `json:"total"`
+		Fees  []testMoney `json:"fees"`
+		Tip   *testMoney  `json:"tip"`
+	}
+	opts := []Option{WithConverter(parseTestMoney), WithYAML(true)}
+	inputs := []interface{}{
+		`{"total":"12,50 EUR","fees":["1,00 EUR","2,00 EUR"],"tip":"0,20 EUR"}`,
+		url.Values{"total": {"12,50 EUR"}, "fees": {"1,00 EUR", "2,00 EUR"}, "tip": {"0,20 EUR"}},
+		"total: 12,50 EUR\nfees: [\"1,00 EUR\", \"2,00 EUR\"]\ntip: 0,20 EUR\n",
+	}
+	for i, in := range inputs {
+		got, err := Transform[Invoice](in, opts...)
+		if err != nil {
+			t.Fatalf("input %d: %v", i, err)
+		}
+		if got.Total.Cents != 1250 || got.Total.Currency != "EUR" {
+			t.Fatalf("input %d: total mismatch: %+v", i, got.Total)
+		}
+		if len(got.Fees) != 2 || got.Fees[1].Cents != 200 {
+			t.Fatalf("input %d: fees mismatch: %+v", i, got.Fees)
+		}
+		if got.Tip == nil || got.Tip.Cents != 20 {
+			t.Fatalf("input %d: tip mismatch: %+v", i, got.Tip)
+		}
+	}
+}
+
+func TestConverterGlobalAndBridge(t *testing.T) {
+	type Row struct {
+		Name  string `json:"name"`
+		Price int64  `json:"price" databridge:"conv=tenfold"`
+	}
+	RegisterNamedConverter("tenfold", func(v any) (int64, error) {
+		i, err := strconv.ParseInt(coerceToString(v), 10, 64)
+		return i * 10, err
+	})
+	rows, err := Transform[[]Row]("name,price\na,1\nb,2\n")
+	if err != nil {
+		t.Fatalf("csv with named converter failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Price != 10 || rows[1].Price != 20 {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+
+	// a Bridge-scoped converter overrides the global one
+	b := NewBridge(WithNamedConverter("tenfold", func(v any) (int64, error) { return 7, nil }))
+	var r Row
+	if err := b.TransformToStructUniversal(`{"name":"x","price":"3"}`, &r); err != nil {
+		t.Fatalf("bridge transform failed: %v", err)
+	}
+	if r.Price != 7 {
+		t.Fatalf("bridge converter not applied: %+v", r)
+	}
+}
+
+func TestConverterErrorHasFieldPath(t *testing.T) {
+	type Line struct {
+		Amount testMoney `json:"amount"`
+	}
+	type Order struct {
+		Lines []Line `json:"lines"`
+	}
+	_, err := Transform[Order](`{"lines":[{"amount":"1,00 EUR"},{"amount":"oops"}]}`, WithConverter(parseTestMoney))
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("expected FieldError, got %v", err)
+	}
+	if fe.Path != "lines[1].amount" {
+		t.Fatalf("unexpected path %q", fe.Path)
+	}
+
+	type Tagged struct {
+		V string `json:"v" databridge:"conv=missing"`
+	}
+	if _, err := Transform[Tagged](`{"v":"x"}`); err == nil || !strings.Contains(err.Error(), `unknown converter "missing"`) {
+		t.Fatalf("expected unknown converter error, got %v", err)
+	}
+}
+
+type testCurrency string
+
+func TestConvertersDisableFastPath(t *testing.T) {
+	type X struct {
+		C testCurrency `json:"c"`
+		N int          `json:"n"`
+		S string       `json:"s" databridge:"conv=upper"`
+	}
+	upper := func(v any) (string, error) { return strings.ToUpper(coerceToString(v)), nil }
+	upperCurrency := func(v any) (testCurrency, error) { return testCurrency(strings.ToUpper(coerceToString(v))), nil }
+	in := `{"c":"eur","s":"abc"}`
+	want := X{C: "EUR", S: "ABC"}
+	got, err := FromJSONString[X](in, WithConverter(upperCurrency), WithNamedConverter("upper", upper))
+	if err != nil || got != want {
+		t.Fatalf("per-call converters skipped: %+v err=%v", got, err)
+	}
+
+	type Y struct {
+		M testMoney `json:"m"`
+	}
+	RegisterConverter(parseTestMoney)
+	defer func() {
+		globalConvertersMu.Lock()
+		delete(globalConverters.byType, reflect.TypeOf(testMoney{}))
+		globalConvertersMu.Unlock()
+	}()
+	y, err := FromJSONString[Y](`{"m":"1,50 EUR"}`)
+	if err != nil || y.M.Cents != 150 {
+		t.Fatalf("registered converter skipped: %+v err=%v", y, err)
+	}
+}
@@ -2,10 +2,12 @@ package databridge
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/url"
 	"reflect"
 	"time"
@@ -14,8 +16,23 @@ import (
 var (
 	ErrUnsupportedInput = errors.New("databridge: unsupported input type")
 	ErrDecodeFailed     = errors.New("databridge: failed to decode input into target")
+	ErrRootNotFound     = errors.New("databridge: root path not found")
 )
 
+// FieldError reports a failure to convert the value supplied for a target field.
+// Path is the dotted JSON path of the field, with slice indices in brackets
+// (e.g. "items[2].price").
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("databridge: field %q: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
 // Config and options ---------------------------------------------------------
 
 type config struct {
@@ -25,12 +42,101 @@ type config struct {
 	Logger          func(format string, args ...interface{})
 	AllowNumberConv bool
 	KeyNormalizer   func(string) string
-	// internal hint to enable cache for default normalizer
-	isDefaultKeyNormalizer bool
+	StrictNumbers   bool
+	NumberFormat    *NumberFormat
+	TrueWords       []string
+	FalseWords      []string
+	TimeLayouts     []string
+	TimeLocation    *time.Location
+	Merge           bool
+	SliceStrategy   SliceStrategy
+	MergeKey        string
+	Root            string
+	Pipeline        []Step
+	StringPolicy    *StringPolicy
+	CollisionError  bool
+	FuzzyThreshold  float64
+	MaxBytes        int64
+	MaxDepth        int
+	MaxKeys         int
+	MaxArrayLen     int
+	MaxCSVRows      int
+	// per-call converters registered via WithConverter / WithNamedConverter
+	converters converterSet
+	// presence collects supplied field paths for TransformWithPresence
+	presence Presence
+	// keyMatcherID names the built-in key matching strategy in use; it keys the
+	// field lookup cache and is empty for a custom KeyNormalizer
+	keyMatcherID string
+	// lookups caches field lookups for a custom KeyNormalizer during one call
+	lookups map[reflect.Type]*fieldLookupEntry
+	// key collisions found during the call
+	collisions    []KeyCollision
+	collisionSeen map[string]bool
+	// explain collects the report of TransformExplain
+	explain *Explanation
+	// ctx is the context of TransformContext, passed to hooks and converters
+	ctx context.Context
+	// slog receives structured events (WithSlog)
+	slog *slog.Logger
+	// observer receives phase events (WithObserver); phase is the current phase
+	observer            Observer
+	phase               string
+	started, phaseStart time.Time
+	// redactKeys holds the normalized JSON names of WithRedactKeys
+	redactKeys map[string]bool
+	// schema validates parsed input (WithSchema); schemaErr reports a bad schema
+	schema    *Schema
+	schemaErr error
+	// textMap and textRows hold the values of form and CSV input as text, for
+	// WithSchema to check them as strings where the schema asks for strings
+	textMap  map[string]interface{}
+	textRows []map[string]interface{}
 }
 
 type Option func(*config)
 
+// allowFastPath reports whether JSON may be decoded straight into the output,
+// skipping the mapping phase.
+func (c *config) allowFastPath() bool {
+	return !c.NormalizeKeys && !c.StrictNumbers && c.presence == nil && !c.Merge && c.Root == "" && len(c.Pipeline) == 0 &&
+		c.StringPolicy == nil && c.schema == nil && c.schemaErr == nil
+}
+
+// fastPathSkipReason returns why raw cannot be decoded straight into a value of
+// type t, or "" if it can be tried.
+func (c *config) fastPathSkipReason(raw []byte, t reflect.Type) string {
+	switch {
+	case !c.allowFastPath():
+		return "options require mapping"
+	case !isLikelyJSON(raw):
+		return "not JSON"
+	case typeHas(t, featureMapping):
+		return "target has path, str or conv tags or BeforeBind hooks"
+	case c.hasTypeConverter(t):
+		return "target has fields with converters"
+	}
+	return ""
+}
+
+// newConfig returns the default configuration with opts applied in order.
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		EnableYAML:      false,
+		NormalizeKeys:   true,
+		Strict:          false,
+		Logger:          func(string, ...interface{}) {},
+		AllowNumberConv: true,
+		KeyNormalizer:   defaultNormalizer,
+		keyMatcherID:    "ascii",
+		ctx:             context.Background(),
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+	return cfg
+}
+
 func WithYAML(enabled bool) Option {
 	return func(c *config) { c.EnableYAML = enabled }
 }
@@ -51,11 +157,19 @@ func WithNumberConversion(enabled bool) Option {
 	return func(c *config) { c.AllowNumberConv = enabled }
 }
 
+// WithStrictNumbers rejects numeric values that would be silently altered on the
+// way into their target field: fractional values for integer fields, negative
+// values for unsigned fields, and integers too large to be exact as floats.
+// Out-of-range values are rejected with a FieldError in either mode.
+func WithStrictNumbers(enabled bool) Option {
+	return func(c *config) { c.StrictNumbers = enabled }
+}
+
 func WithKeyNormalizer(fn func(string) string) Option {
 	return func(c *config) {
 		c.KeyNormalizer = fn
-		// mark as non-default when user supplies a custom fn
-		c.isDefaultKeyNormalizer = false
+		// a custom fn cannot share cached lookups with the built-in strategies
+		c.keyMatcherID = ""
 	}
 }
 
@@ -80,6 +194,15 @@ func WithKeyNormalizer(fn func(string) string) Option {
 //   - If output is slice type, CSV or multi-row input will map to slice elements.
 //   - If output is a struct and CSV contains multiple rows, the first row is used.
 func TransformToStructUniversal(input interface{}, output interface{}, opts ...Option) error {
+	return TransformContext(context.Background(), input, output, opts...)
+}
+
+// TransformContext is TransformToStructUniversal with cancellation: ctx is checked
+// between the read, parse, map and decode phases and between rows, and a
+// cancelled transform returns a *CanceledError wrapping ctx.Err(). ctx is passed
+// to BeforeBindContext / AfterBindContext hooks and to converters registered with
+// WithConverterContext.
+func TransformContext(ctx context.Context, input interface{}, output interface{}, opts ...Option) (err error) {
 	// validate output
 	if output == nil {
 		return fmt.Errorf("output must be non-nil pointer")
@@ -89,19 +212,20 @@ func TransformToStructUniversal(input interface{}, output interface{}, opts ...O
 		return fmt.Errorf("output must be a non-nil pointer")
 	}
 
-	// default config
-	cfg := &config{
-		EnableYAML:      false,
-		NormalizeKeys:   true,
-		Strict:          false,
-		Logger:          func(string, ...interface{}) {},
-		AllowNumberConv: true,
-		KeyNormalizer:   defaultNormalizer,
-		// default path uses our built-in normalizer
-		isDefaultKeyNormalizer: true,
-	}
-	for _, o := range opts {
-		o(cfg)
+	cfg := newConfig(opts)
+	cfg.ctx = ctx
+	cfg.observeStart()
+	// dropped is set when the pipeline drops a single object: nothing is bound
+	// then, so AfterBind hooks do not run
+	var dropped bool
+	defer func() {
+		if err == nil && !dropped {
+			err = runAfterBind(ctx, outV.Elem(), "")
+		}
+		cfg.observeDone(err)
+	}()
+	if err := cfg.checkContext("read", 0); err != nil {
+		return err
 	}
 
 	// parse input into an intermediate structure:
@@ -110,75 +234,51 @@ func TransformToStructUniversal(input interface{}, output interface{}, opts ...O
 	var (
 		intermediateMap map[string]interface{}
 		intermediateArr []map[string]interface{}
-		err             error
 	)
 
-	switch v := input.(type) {
-	case string:
-		b := []byte(v)
-		if !cfg.NormalizeKeys && isLikelyJSON(b) {
-			if ok, ferr := fastJSONIntoOutput(b, outV, cfg); ok {
-				return ferr
-			}
-		}
-		intermediateMap, intermediateArr, err = parseBytesDetect(b, cfg)
-	case []byte:
-		if !cfg.NormalizeKeys && isLikelyJSON(v) {
-			if ok, ferr := fastJSONIntoOutput(v, outV, cfg); ok {
-				return ferr
-			}
-		}
-		intermediateMap, intermediateArr, err = parseBytesDetect(v, cfg)
-	case *bytes.Buffer:
-		b := v.Bytes()
-		if !cfg.NormalizeKeys && isLikelyJSON(b) {
-			if ok, ferr := fastJSONIntoOutput(b, outV, cfg); ok {
-				return ferr
+	raw, isRaw, err := readInput(input, cfg.MaxBytes)
+	if err != nil {
+		return err
+	}
+	if err := cfg.checkContext("parse", 0); err != nil {
+		return err
+	}
+	if isRaw {
+		if skip := cfg.fastPathSkipReason(raw, outV.Elem().Type()); skip == "" {
+			if err := cfg.scanJSONLimits(raw); err != nil {
+				return err
 			}
-		}
-		intermediateMap, intermediateArr, err = parseBytesDetect(b, cfg)
-	case io.Reader:
-		b, rerr := io.ReadAll(v)
-		if rerr != nil {
-			return fmt.Errorf("databridge: read error: %w", rerr)
-		}
-		if !cfg.NormalizeKeys && isLikelyJSON(b) {
-			if ok, ferr := fastJSONIntoOutput(b, outV, cfg); ok {
+			if ok, ferr := fastJSONIntoOutput(raw, outV, cfg); ok {
+				cfg.formatDetected("json")
+				if cfg.explain != nil {
+					cfg.explain.FastPath = true
+				}
+				cfg.logEvent(slog.LevelDebug, "fast path taken")
 				return ferr
 			}
-		}
-		intermediateMap, intermediateArr, err = parseBytesDetect(b, cfg)
-	case url.Values:
-		intermediateMap = formValuesToMapWithDots(v, cfg)
-	case map[string]interface{}:
-		intermediateMap = cloneMap(v)
-	default:
-		// if struct / ptr to struct: marshal to JSON then parse
-		rv := reflect.ValueOf(v)
-		if rv.Kind() == reflect.Struct || (rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct) {
-			j, jerr := json.Marshal(v)
-			if jerr != nil {
-				return fmt.Errorf("databridge: marshal struct: %w", jerr)
-			}
-			intermediateMap, intermediateArr, err = parseBytesDetect(j, cfg)
+			cfg.logEvent(slog.LevelDebug, "fast path skipped", slog.String("reason", "direct decode failed"))
 		} else {
-			return fmt.Errorf("%w: %T", ErrUnsupportedInput, v)
+			cfg.logEvent(slog.LevelDebug, "fast path skipped", slog.String("reason", skip))
 		}
+		intermediateMap, intermediateArr, err = parseBytesDetect(raw, cfg)
+	} else {
+		intermediateMap, intermediateArr, err = parseStructuredInput(input, cfg)
 	}
 	if err != nil {
 		return err
 	}
 
-	// normalize keys if needed
-	if cfg.NormalizeKeys && cfg.KeyNormalizer != nil {
-		if intermediateMap != nil {
-			intermediateMap = normalizeMapKeysDeep(intermediateMap, cfg.KeyNormalizer)
-		}
-		for i := range intermediateArr {
-			intermediateArr[i] = normalizeMapKeysDeep(intermediateArr[i], cfg.KeyNormalizer)
-		}
+	// select the sub-document and reshape records; a dropped single object
+	// leaves output unchanged
+	var keep bool
+	if intermediateMap, intermediateArr, keep, err = cfg.reshape(intermediateMap, intermediateArr); err != nil || !keep {
+		dropped = !keep
+		return err
 	}
 
+	// keys are normalized while mapping, where collisions can be resolved against
+	// the target's fields
+
 	// determine output kind (struct or slice)
 	outElem := outV.Elem()
 	outElemType := outElem.Type()
@@ -189,36 +289,54 @@ func TransformToStructUniversal(input interface{}, output interface{}, opts ...O
 		elemType := outElemType.Elem()
 		prepared := make([]map[string]interface{}, 0, len(intermediateArr))
 		for _, m := range intermediateArr {
-			mapped, unmatched := mapToStructKeysRecursive(m, elemType, cfg)
-			if cfg.Strict && len(unmatched) > 0 {
-				return fmt.Errorf("databridge: strict mode - unknown fields present: %v", unmatched)
+			if err := cfg.checkContext("map", len(prepared)); err != nil {
+				return err
+			}
+			path := fmt.Sprintf("[%d]", len(prepared))
+			mapped, err := cfg.prepareRecord(m, elemType, path)
+			cfg.rowDone(len(prepared), err)
+			if err != nil {
+				return err
 			}
-			mapped = coerceAccordingToType(mapped, elemType)
 			prepared = append(prepared, mapped)
 		}
+		var rows interface{} = prepared
+		if cfg.Merge {
+			merged := make([]interface{}, len(prepared))
+			for i, m := range prepared {
+				merged[i] = m
+			}
+			if rows, err = mergeSliceValue(outElem, merged, cfg.SliceStrategy, cfg.mergeKey()); err != nil {
+				return err
+			}
+		}
+		if err := cfg.checkContext("decode", len(prepared)); err != nil {
+			return err
+		}
 		// convert []map -> []byte JSON -> unmarshal into output
-		j, merr := json.Marshal(prepared)
+		j, merr := json.Marshal(rows)
 		if merr != nil {
 			return fmt.Errorf("databridge: marshal intermediate array: %w", merr)
 		}
 		if cfg.Strict {
-			dec := json.NewDecoder(bytes.NewReader(j))
-			dec.DisallowUnknownFields()
-			if derr := dec.Decode(output); derr != nil {
-				return fmt.Errorf("%w: %v", ErrDecodeFailed, derr)
+			if derr := decodeOutput(j, output, true); derr != nil {
+				return fmt.Errorf("%w: %v", ErrDecodeFailed, cfg.redactDecodeError(derr, outElemType))
 			}
 			return nil
 		}
-		if uerr := json.Unmarshal(j, output); uerr != nil {
+		if uerr := decodeOutput(j, output, false); uerr != nil {
 			// best effort convert and retry
+			uerr = cfg.redactDecodeError(uerr, outElemType)
 			cfg.Logger("unmarshal slice failed: %v; attempting best-effort conversion", uerr)
+			cfg.logEvent(slog.LevelWarn, "best-effort conversion", slog.Any("error", uerr))
+			cfg.explain.decodeFailed(uerr)
 			converted := make([]map[string]interface{}, 0, len(prepared))
 			for _, mm := range prepared {
 				converted = append(converted, bestEffortConvert(mm))
 			}
 			j2, _ := json.Marshal(converted)
-			if err2 := json.Unmarshal(j2, output); err2 != nil {
-				return fmt.Errorf("%w: %v", ErrDecodeFailed, err2)
+			if err2 := decodeOutput(j2, output, false); err2 != nil {
+				return fmt.Errorf("%w: %v", ErrDecodeFailed, cfg.redactDecodeError(err2, outElemType))
 			}
 		}
 		return nil
@@ -233,44 +351,254 @@ func TransformToStructUniversal(input interface{}, output interface{}, opts ...O
 		}
 	}
 
-	// map incoming keys to struct field JSON names (struct-aware)
-	mapped, unmatched := mapToStructKeysRecursive(intermediateMap, outElemType, cfg)
+	if err := cfg.checkContext("map", 0); err != nil {
+		return err
+	}
+	mapped, err := cfg.prepareRecord(intermediateMap, outElemType, "")
+	if err != nil {
+		return err
+	}
+
+	if cfg.Merge {
+		if err := prepareMerge(mapped, outElem, cfg, ""); err != nil {
+			return err
+		}
+	}
+
+	if err := cfg.checkContext("decode", 0); err != nil {
+		return err
+	}
+	return cfg.decodeRecord(mapped, output)
+}
 
-	// strict top-level check
-	if cfg.Strict && len(unmatched) > 0 {
-		return fmt.Errorf("databridge: strict mode - unknown fields present: %v", unmatched)
+// reshape validates the parsed input against WithSchema and applies WithRoot and
+// WithPipeline to it. keep is false when the pipeline dropped a single object.
+func (c *config) reshape(m map[string]interface{}, arr []map[string]interface{}) (map[string]interface{}, []map[string]interface{}, bool, error) {
+	// validate the input as parsed
+	if c.schema != nil || c.schemaErr != nil {
+		if err := c.validateInput(m, arr); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	// select the sub-document to decode
+	if c.Root != "" {
+		var err error
+		if m, arr, err = selectRoot(m, arr, c); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	// reshape records
+	if len(c.Pipeline) > 0 {
+		var keep bool
+		if m, arr, keep = applyPipeline(m, arr, c.Pipeline); !keep {
+			return nil, nil, false, nil
+		}
 	}
+	return m, arr, true, nil
+}
+
+// prepareRecord maps the keys of one input record to typ's JSON names, enforces
+// strict mode and collision errors, runs BeforeBind hooks and coerces the values.
+// path is the record's path in the output ("" or "[i]").
+func (c *config) prepareRecord(m map[string]interface{}, typ reflect.Type, path string) (map[string]interface{}, error) {
+	// map incoming keys to struct field JSON names (struct-aware)
+	leave := c.explain.enter(path)
+	mapped, unmatched, matched := mapToStructKeysRecursive(m, typ, c)
+	leave()
+	c.presence.add(path, matched)
 
-	// marshal mapped and unmarshal into output
+	if c.Strict && len(unmatched) > 0 {
+		return nil, c.unknownFieldsError(typ, unmatched)
+	}
+	if len(unmatched) > 0 && c.slog != nil {
+		c.logEvent(slog.LevelInfo, "unmatched keys", slog.String("record", path), slog.Any("keys", unmatched))
+	}
+	if err := c.collisionError(); err != nil {
+		return nil, err
+	}
+	if err := runBeforeBind(c.ctx, mapped, typ, path); err != nil {
+		return nil, err
+	}
 	// Coerce primitive types according to target shape to handle strings like "30" -> int
-	mapped = coerceAccordingToType(mapped, outElemType)
+	return coerceAccordingToType(mapped, typ, c, path)
+}
 
+// decodeRecord marshals a prepared record and decodes it into output, retrying
+// with best-effort number conversion outside strict mode.
+func (c *config) decodeRecord(mapped map[string]interface{}, output interface{}) error {
 	j, merr := json.Marshal(mapped)
 	if merr != nil {
 		return fmt.Errorf("databridge: marshal mapped: %w", merr)
 	}
+	t := reflect.TypeOf(output)
 
-	if cfg.Strict {
-		dec := json.NewDecoder(bytes.NewReader(j))
-		dec.DisallowUnknownFields()
-		if derr := dec.Decode(output); derr != nil {
-			return fmt.Errorf("%w: %v", ErrDecodeFailed, derr)
+	if c.Strict {
+		if derr := decodeOutput(j, output, true); derr != nil {
+			return fmt.Errorf("%w: %v", ErrDecodeFailed, c.redactDecodeError(derr, t))
 		}
 		return nil
 	}
 
-	if uerr := json.Unmarshal(j, output); uerr != nil {
-		cfg.Logger("unmarshal to output failed: %v; trying best-effort conversion", uerr)
+	if uerr := decodeOutput(j, output, false); uerr != nil {
+		uerr = c.redactDecodeError(uerr, t)
+		c.Logger("unmarshal to output failed: %v; trying best-effort conversion", uerr)
+		c.logEvent(slog.LevelWarn, "best-effort conversion", slog.Any("error", uerr))
+		c.explain.decodeFailed(uerr)
 		relaxed := bestEffortConvert(mapped)
 		j2, _ := json.Marshal(relaxed)
-		if uerr2 := json.Unmarshal(j2, output); uerr2 != nil {
-			return fmt.Errorf("%w: %v", ErrDecodeFailed, uerr2)
+		if uerr2 := decodeOutput(j2, output, false); uerr2 != nil {
+			return fmt.Errorf("%w: %v", ErrDecodeFailed, c.redactDecodeError(uerr2, t))
 		}
 	}
 
 	return nil
 }
 
+// decodeOutput decodes JSON into output, rejecting unknown fields when strict.
+// In generic outputs (interface{} and maps of interface{} values) numbers are
+// float64 as with encoding/json, except integers a float64 cannot hold exactly,
+// which are int64 or uint64 (or json.Number beyond 64 bits).
+func decodeOutput(j []byte, output interface{}, strict bool) error {
+	dec := json.NewDecoder(bytes.NewReader(j))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	generic := genericOutput(reflect.TypeOf(output))
+	if generic {
+		dec.UseNumber()
+	}
+	err := dec.Decode(output)
+	if generic {
+		exactNumbers(reflect.ValueOf(output))
+	}
+	return err
+}
+
+// exactNumbers replaces the json.Numbers in the generic values of v.
+func exactNumbers(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			exactNumbers(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			exactNumbers(v.Index(i))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			if e := v.MapIndex(k); e.IsValid() && !e.IsNil() {
+				v.SetMapIndex(k, reflect.ValueOf(exactNumber(e.Interface())))
+			}
+		}
+	case reflect.Interface:
+		if !v.IsNil() && v.CanSet() {
+			v.Set(reflect.ValueOf(exactNumber(v.Interface())))
+		}
+	}
+}
+
+// exactNumber is exactNumbers for a generic value.
+func exactNumber(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		n := coerceNumberValue(x)
+		if i, ok := n.(int64); ok && i >= -1<<53 && i <= 1<<53 {
+			return float64(i)
+		}
+		return n
+	case map[string]interface{}:
+		for k, e := range x {
+			x[k] = exactNumber(e)
+		}
+	case []interface{}:
+		for i, e := range x {
+			x[i] = exactNumber(e)
+		}
+	}
+	return v
+}
+
+// genericOutput reports whether t, through pointers, slices and arrays, is
+// interface{} or a map of interface{} values.
+func genericOutput(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Interface || (t.Kind() == reflect.Map && t.Elem().Kind() == reflect.Interface)
+}
+
+// readInput returns the bytes of byte-oriented inputs (string, []byte,
+// *bytes.Buffer, io.Reader). isRaw is false for structured inputs. Input longer
+// than maxBytes (if > 0) fails with a *LimitError; readers are read at most one
+// byte past it.
+func readInput(input interface{}, maxBytes int64) (b []byte, isRaw bool, err error) {
+	switch v := input.(type) {
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	case *bytes.Buffer:
+		b = v.Bytes()
+	case io.Reader:
+		if maxBytes > 0 {
+			v = io.LimitReader(v, maxBytes+1)
+		}
+		var rerr error
+		if b, rerr = io.ReadAll(v); rerr != nil {
+			return nil, true, fmt.Errorf("databridge: read error: %w", rerr)
+		}
+	default:
+		return nil, false, nil
+	}
+	if maxBytes > 0 && int64(len(b)) > maxBytes {
+		return nil, true, &LimitError{Limit: "bytes", Max: maxBytes}
+	}
+	return b, true, nil
+}
+
+// parseStructuredInput converts url.Values, maps and structs into the intermediate shape.
+func parseStructuredInput(input interface{}, cfg *config) (map[string]interface{}, []map[string]interface{}, error) {
+	switch v := input.(type) {
+	case url.Values:
+		cfg.formatDetected("form")
+		cfg.keepFormText(v)
+		m := formValuesToMapWithDots(v, cfg)
+		return m, nil, cfg.checkParsedLimits(m, nil)
+	case map[string]interface{}:
+		cfg.formatDetected("map")
+		return cloneMap(v), nil, cfg.checkParsedLimits(v, nil)
+	default:
+		// if struct / ptr to struct: marshal to JSON then parse
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Struct || (rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct) {
+			j, jerr := json.Marshal(v)
+			if jerr != nil {
+				return nil, nil, fmt.Errorf("databridge: marshal struct: %w", jerr)
+			}
+			_, m, arr, err := parseBytesFormat(j, cfg)
+			if err == nil {
+				cfg.formatDetected("struct")
+			}
+			return m, arr, err
+		}
+		return nil, nil, fmt.Errorf("%w: %T", ErrUnsupportedInput, v)
+	}
+}
+
+// parseInput parses any supported input into the intermediate shape: a single
+// map, or a slice of maps for multi-row formats.
+func parseInput(input interface{}, cfg *config) (map[string]interface{}, []map[string]interface{}, error) {
+	raw, isRaw, err := readInput(input, cfg.MaxBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isRaw {
+		return parseBytesDetect(raw, cfg)
+	}
+	return parseStructuredInput(input, cfg)
+}
+
 // isLikelyJSON performs a quick check for JSON payloads ('{' or '[' after trimming spaces/BOM).
 func isLikelyJSON(b []byte) bool {
 	// strip potential UTF-8 BOM
@@ -299,11 +627,7 @@ func fastJSONIntoOutput(b []byte, outV reflect.Value, cfg *config) (bool, error)
 	outElem := outV.Elem()
 	outElemType := outElem.Type()
 	tmpPtr := reflect.New(outElemType)
-	dec := json.NewDecoder(bytes.NewReader(b))
-	if cfg.Strict {
-		dec.DisallowUnknownFields()
-	}
-	if err := dec.Decode(tmpPtr.Interface()); err != nil {
+	if err := decodeOutput(b, tmpPtr.Interface(), cfg.Strict); err != nil {
 		return false, nil
 	}
 	// success: set into caller's output
@@ -311,6 +635,27 @@ func fastJSONIntoOutput(b []byte, outV reflect.Value, cfg *config) (bool, error)
 	return true, nil
 }
 
+// ParseRecords parses input as Transform does but without mapping it to a type.
+// It returns the records of CSV or JSON array input, or the one object of other
+// input, with the detected format (json, form, yaml, xml, csv, text, map, struct
+// or empty). WithRoot, WithPipeline, WithSchema and the limits apply.
+func ParseRecords(input interface{}, opts ...Option) ([]map[string]interface{}, string, error) {
+	e := &Explanation{}
+	cfg := newConfig(append(opts, func(c *config) { c.explain = e }))
+	m, rows, err := parseInput(input, cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	m, rows, keep, err := cfg.reshape(m, rows)
+	if err != nil || !keep {
+		return nil, e.Format, err
+	}
+	if rows == nil {
+		rows = []map[string]interface{}{m}
+	}
+	return rows, e.Format, nil
+}
+
 // Transform is a generic convenience wrapper that returns a value of type T.
 // Example: user := databridge.Transform[User](formOrJSON)
 func Transform[T any](input interface{}, opts ...Option) (T, error) {
@@ -339,6 +684,30 @@ func TransformToJSON(input interface{}, outputPtr interface{}, opts ...Option) (
 	return json.Marshal(outputPtr)
 }
 
+// Bridge is a reusable transformer that applies the same options (including
+// converters registered with WithConverter) to every call.
+type Bridge struct {
+	opts []Option
+}
+
+// NewBridge returns a Bridge applying opts to every transform.
+func NewBridge(opts ...Option) *Bridge {
+	return &Bridge{opts: append([]Option(nil), opts...)}
+}
+
+// TransformToStructUniversal behaves like the package-level function, applying the
+// Bridge's options first and then opts.
+func (b *Bridge) TransformToStructUniversal(input interface{}, output interface{}, opts ...Option) error {
+	return TransformToStructUniversal(input, output, b.options(opts)...)
+}
+
+// options returns the Bridge options followed by per-call opts.
+func (b *Bridge) options(opts []Option) []Option {
+	all := make([]Option, 0, len(b.opts)+len(opts))
+	all = append(all, b.opts...)
+	return append(all, opts...)
+}
+
 // FromJSON decodes JSON bytes into T using the fastest path (no key normalization),
 // honoring Strict mode if provided via options.
 // Use when your payload keys already match your struct json tags.
@@ -0,0 +1,237 @@
+package databridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Explanation reports how TransformExplain decoded its input. It marshals to
+// JSON as is; Table renders it for humans.
+type Explanation struct {
+	Format    string         `json:"format"`    // detected input format: json, form, yaml, xml, csv, text, map or struct
+	FastPath  bool           `json:"fast_path"` // JSON was decoded directly, without key mapping or coercion
+	Keys      []KeyTrace     `json:"keys"`
+	Coercions []Coercion     `json:"coercions"`
+	Dropped   []DroppedValue `json:"dropped"`
+
+	path   string // target path of the object being mapped
+	masked int    // depth of sensitive fields being coerced
+}
+
+// KeyTrace describes how one input key was matched. Source is the input key
+// prefixed with the target path of its object; Field is the target field path,
+// empty when the key matched nothing. Rule is one of "json name", "normalized",
+// "field name", "path", "fuzzy", "collision" (another key won the field) or
+// "unknown".
+type KeyTrace struct {
+	Source     string `json:"source"`
+	Normalized string `json:"normalized"`
+	Field      string `json:"field,omitempty"`
+	Rule       string `json:"rule"`
+}
+
+// Coercion records a value converted on its way into a field, From being the
+// input kind (string, number, bool, object, ...) and To the field's Go type.
+type Coercion struct {
+	Path string `json:"path"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (c Coercion) String() string { return c.From + "→" + c.To }
+
+// DroppedValue is an input value that did not reach its field intact: emptied
+// to null, truncated or clamped by best-effort conversion, or rejected by the
+// decoder.
+type DroppedValue struct {
+	Path   string `json:"path"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// TransformExplain decodes input into output like TransformToStructUniversal and
+// reports how each key was matched and each value converted. The report is
+// returned even when decoding fails, covering the steps taken until then.
+//
+// Example:
+//
+//	report, err := databridge.TransformExplain(body, &user)
+//	fmt.Print(report.Table())
+func TransformExplain(input interface{}, output interface{}, opts ...Option) (*Explanation, error) {
+	e := &Explanation{}
+	opts = append(opts, func(c *config) { c.explain = e })
+	err := TransformToStructUniversal(input, output, opts...)
+	e.sort()
+	return e, err
+}
+
+// Table renders the report as aligned text tables.
+func (e *Explanation) Table() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "format: %s\nfast path: %t\n", e.Format, e.FastPath)
+	section := func(header string, rows [][]string) {
+		if len(rows) == 0 {
+			return
+		}
+		b.WriteByte('\n')
+		tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, header)
+		for _, r := range rows {
+			fmt.Fprintln(tw, strings.Join(r, "\t"))
+		}
+		tw.Flush()
+	}
+	var rows [][]string
+	for _, k := range e.Keys {
+		rows = append(rows, []string{k.Source, k.Normalized, orDash(k.Field), k.Rule})
+	}
+	section("KEY\tNORMALIZED\tFIELD\tRULE", rows)
+	rows = nil
+	for _, c := range e.Coercions {
+		rows = append(rows, []string{c.Path, c.String()})
+	}
+	section("FIELD\tCOERCION", rows)
+	rows = nil
+	for _, d := range e.Dropped {
+		rows = append(rows, []string{d.Path, orDash(d.Value), d.Reason})
+	}
+	section("FIELD\tVALUE\tREASON", rows)
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// The methods below are no-ops on a nil *Explanation, so call sites need no
+// checks when explaining is off.
+
+// enter descends into the field or row seg of the object being mapped and
+// returns a func restoring the previous path.
+func (e *Explanation) enter(seg string) func() {
+	if e == nil {
+		return func() {}
+	}
+	prev := e.path
+	e.path = joinSubPath(prev, seg)
+	return func() { e.path = prev }
+}
+
+// key records the match of input key k (normalized to nk) in the current object;
+// field is the JSON name of the matched field.
+func (e *Explanation) key(k, nk, field, rule string) {
+	if e == nil {
+		return
+	}
+	kt := KeyTrace{Source: joinSubPath(e.path, k), Normalized: nk, Rule: rule}
+	if field != "" {
+		kt.Field = joinSubPath(e.path, field)
+	}
+	e.Keys = append(e.Keys, kt)
+}
+
+// coerced records the conversion of in to out for the field at path of type t.
+func (e *Explanation) coerced(path string, in, out interface{}, t reflect.Type) {
+	if e == nil {
+		return
+	}
+	from, to := valueKind(in), valueKind(out)
+	if (from == "object" || from == "array") && from == to {
+		return // containers are reported per element
+	}
+	if from == to && reflect.DeepEqual(in, out) {
+		return
+	}
+	e.Coercions = append(e.Coercions, Coercion{Path: path, From: from, To: t.String()})
+	switch {
+	case out == nil && in != nil:
+		e.drop(path, in, "stored as null")
+	case from == "object" && to == "string":
+		e.drop(path, in, "object reduced to "+e.show(out))
+	case from == "number" && to == "number" && fmt.Sprint(in) != fmt.Sprint(out):
+		e.drop(path, in, "stored as "+e.show(out))
+	}
+}
+
+// redacting masks the values reported until the returned func is called, while a
+// sensitive field is coerced.
+func (e *Explanation) redacting() func() {
+	if e == nil {
+		return func() {}
+	}
+	e.masked++
+	return func() { e.masked-- }
+}
+
+// show renders v for a report, or Redacted inside a sensitive field.
+func (e *Explanation) show(v interface{}) string {
+	if e.masked > 0 {
+		return Redacted
+	}
+	return formatValue(v)
+}
+
+// decodeFailed records a value the decoder rejected before the best-effort retry.
+func (e *Explanation) decodeFailed(err error) {
+	var te *json.UnmarshalTypeError
+	if e == nil || !errors.As(err, &te) {
+		return
+	}
+	e.drop(te.Field, nil, fmt.Sprintf("cannot decode %s into %s", te.Value, te.Type))
+}
+
+func (e *Explanation) drop(path string, v interface{}, reason string) {
+	d := DroppedValue{Path: path, Reason: reason}
+	if v != nil {
+		d.Value = e.show(v)
+	}
+	e.Dropped = append(e.Dropped, d)
+}
+
+// sort orders the entries by path; mapping visits keys in map order.
+func (e *Explanation) sort() {
+	sort.SliceStable(e.Keys, func(i, j int) bool { return e.Keys[i].Source < e.Keys[j].Source })
+	sort.SliceStable(e.Coercions, func(i, j int) bool { return e.Coercions[i].Path < e.Coercions[j].Path })
+	sort.SliceStable(e.Dropped, func(i, j int) bool { return e.Dropped[i].Path < e.Dropped[j].Path })
+}
+
+// valueKind names the JSON kind of a decoded value.
+func valueKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int64, uint64, float64, json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// formatValue renders a decoded value for reports: strings quoted, objects and
+// arrays as JSON.
+func formatValue(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", x)
+	case map[string]interface{}, []interface{}:
+		if b, err := json.Marshal(x); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
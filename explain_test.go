@@ -0,0 +1,88 @@
+package databridge
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTransformExplain(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Account struct {
+		ID       int64   `json:"id"`
+		FullName string  `json:"display"`
+		Nick     *string `json:"nick"`
+		Count    uint8   `json:"count"`
+		Address  Address `json:"address"`
+	}
+	in := `{"ID":"42","FullName":"Ada","nick":"","count":-3,"address":{"City":"Paris","zip":"75001"},"extra":true}`
+	var a Account
+	rep, err := TransformExplain(in, &a)
+	if err != nil {
+		t.Fatalf("TransformExplain: %v", err)
+	}
+	if rep.Format != "json" || rep.FastPath {
+		t.Fatalf("format=%q fast=%v", rep.Format, rep.FastPath)
+	}
+	want := []KeyTrace{
+		{Source: "FullName", Normalized: "fullname", Field: "display", Rule: "field name"},
+		{Source: "ID", Normalized: "id", Field: "id", Rule: "normalized"},
+		{Source: "address", Normalized: "address", Field: "address", Rule: "json name"},
+		{Source: "address.City", Normalized: "city", Field: "address.city", Rule: "normalized"},
+		{Source: "address.zip", Normalized: "zip", Rule: "unknown"},
+		{Source: "count", Normalized: "count", Field: "count", Rule: "json name"},
+		{Source: "extra", Normalized: "extra", Rule: "unknown"},
+		{Source: "nick", Normalized: "nick", Field: "nick", Rule: "json name"},
+	}
+	if len(rep.Keys) != len(want) {
+		t.Fatalf("keys = %+v", rep.Keys)
+	}
+	for i := range want {
+		if rep.Keys[i] != want[i] {
+			t.Fatalf("key %d = %+v, want %+v", i, rep.Keys[i], want[i])
+		}
+	}
+	var conv []string
+	for _, c := range rep.Coercions {
+		conv = append(conv, c.Path+" "+c.String())
+	}
+	if got := strings.Join(conv, "; "); got != "count number→uint8; id string→int64; nick string→*string" {
+		t.Fatalf("coercions = %s", got)
+	}
+	if len(rep.Dropped) != 2 || rep.Dropped[0].Path != "count" || rep.Dropped[0].Reason != "stored as 0" || rep.Dropped[1].Path != "nick" {
+		t.Fatalf("dropped = %+v", rep.Dropped)
+	}
+
+	table := rep.Table()
+	for _, s := range []string{"format: json", "address.City", "id     string→int64", "count  -3     stored as 0"} {
+		if !strings.Contains(table, s) {
+			t.Fatalf("table lacks %q:\n%s", s, table)
+		}
+	}
+	b, err := json.Marshal(rep)
+	if err != nil || !strings.Contains(string(b), `{"source":"extra","normalized":"extra","rule":"unknown"}`) {
+		t.Fatalf("json = %s, %v", b, err)
+	}
+}
+
+func TestTransformExplainFastPathAndRows(t *testing.T) {
+	type Row struct {
+		Name string `json:"name"`
+	}
+	var r Row
+	rep, err := TransformExplain(`{"name":"x"}`, &r, WithKeyNormalization(false))
+	if err != nil || !rep.FastPath || rep.Format != "json" || len(rep.Keys) != 0 {
+		t.Fatalf("fast path report = %+v, %v", rep, err)
+	}
+
+	var rows []Row
+	rep, err = TransformExplain("Name,age\na,1\nb,2\n", &rows)
+	if err != nil || rep.Format != "csv" {
+		t.Fatalf("csv report = %+v, %v", rep, err)
+	}
+	if rep.Keys[0].Source != "[0].Name" || rep.Keys[0].Field != "[0].name" || rep.Keys[3].Source != "[1].age" {
+		t.Fatalf("row keys = %+v", rep.Keys)
+	}
+}
@@ -2,6 +2,7 @@ package databridge
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -65,3 +66,55 @@ func FuzzTransformCSV(f *testing.F) {
 		_ = TransformToStructUniversal(csv, &rows)
 	})
 }
+
+// Fuzz the size, depth, key and array limits: input either fails with a
+// *LimitError or decodes into a value within the limits
+func FuzzLimits(f *testing.F) {
+	f.Add(`{"a":{"b":[1,2,3]},"x":"y"}`, uint8(2), uint8(2), uint8(2))
+	f.Add(`[{"n":"1"},{"n":[[[2]]]}]`, uint8(3), uint8(1), uint8(1))
+	f.Add(`{"s":"{[,\"]}","t":{}}`, uint8(1), uint8(1), uint8(0))
+	f.Add("a.b.c=1&d=2", uint8(2), uint8(1), uint8(1))
+	f.Add(strings.Repeat("[", 5000), uint8(7), uint8(7), uint8(7))
+	f.Fuzz(func(t *testing.T, input string, depth, keys, arrayLen uint8) {
+		limits := &config{MaxDepth: int(depth%8) + 1, MaxKeys: int(keys%8) + 1, MaxArrayLen: int(arrayLen%8) + 1}
+		var m map[string]interface{}
+		err := TransformToStructUniversal(input, &m, WithMaxBytes(4096), WithMaxDepth(limits.MaxDepth), WithMaxKeys(limits.MaxKeys), WithMaxArrayLen(limits.MaxArrayLen))
+		var le *LimitError
+		if errors.As(err, &le) {
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("LimitError does not wrap ErrLimitExceeded: %v", err)
+			}
+			return
+		}
+		if err == nil && len(input) > 4096 {
+			t.Fatalf("accepted %d bytes over WithMaxBytes(4096)", len(input))
+		}
+		if err == nil {
+			if lerr := limits.checkValueLimits(m, 1); lerr != nil {
+				t.Fatalf("decoded value exceeds limits (%v): %v", lerr, m)
+			}
+		}
+	})
+}
+
+// Fuzz CSV row limits with a random number of rows
+func FuzzLimitsCSV(f *testing.F) {
+	f.Add("a", uint8(3), uint8(2))
+	f.Add("x\"y", uint8(1), uint8(5))
+	f.Fuzz(func(t *testing.T, cell string, rows, max uint8) {
+		var b strings.Builder
+		b.WriteString("name,value\n")
+		for i := 0; i < int(rows%16); i++ {
+			fmt.Fprintf(&b, "%q,%d\n", cell, i)
+		}
+		limit := int(max%16) + 1
+		var out []map[string]interface{}
+		err := TransformToStructUniversal(b.String(), &out, WithMaxCSVRows(limit))
+		if err == nil && len(out) > limit {
+			t.Fatalf("decoded %d rows over WithMaxCSVRows(%d)", len(out), limit)
+		}
+		if err == nil && int(rows%16) > limit {
+			t.Fatalf("accepted %d rows over WithMaxCSVRows(%d)", rows%16, limit)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package databridge
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WithFuzzyMatch enables near-miss key matching with a similarity threshold in
+// (0, 1], e.g. 0.8; 0 disables it. Similarity is 1 - edit distance / length,
+// computed on normalized keys with transpositions counting as one edit, so
+// "frist_name" matches "first_name" at 0.89. Outside strict mode an unknown key
+// is bound to the most similar field not supplied otherwise; in strict mode it is
+// rejected and the error suggests the field ("did you mean ...?").
+func WithFuzzyMatch(threshold float64) Option {
+	return func(c *config) { c.FuzzyThreshold = threshold }
+}
+
+// keySimilarity returns 1 - d/max(len(a), len(b)) where d is the optimal string
+// alignment distance between a and b.
+func keySimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	n, m := len(ra), len(rb)
+	if n == 0 && m == 0 {
+		return 1
+	}
+	d := make([][]int, n+1)
+	for i := range d {
+		d[i] = make([]int, m+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return 1 - float64(d[n][m])/float64(max(n, m))
+}
+
+// bestFieldMatch returns the field whose normalized key is most similar to key,
+// skipping fields in exclude and path-tagged fields. Ties between different
+// fields are ambiguous and yield no match.
+func bestFieldMatch(key string, entry *fieldLookupEntry, exclude map[int]bool, threshold float64) (fieldInfo, bool) {
+	var (
+		best      fieldInfo
+		bestScore float64
+		ambiguous bool
+	)
+	candidates := make([]string, 0, len(entry.fields))
+	for k := range entry.fields {
+		candidates = append(candidates, k)
+	}
+	sort.Strings(candidates)
+	for _, cand := range candidates {
+		info := entry.fields[cand]
+		if exclude[info.Index] || info.Tag.Path != "" {
+			continue
+		}
+		score := keySimilarity(key, cand)
+		switch {
+		case score < threshold || score < bestScore:
+		case score == bestScore && best.Index != info.Index:
+			ambiguous = true
+		case score > bestScore:
+			best, bestScore, ambiguous = info, score, false
+		}
+	}
+	return best, bestScore > 0 && !ambiguous
+}
+
+// suggestField returns the JSON name of the field most similar to the unknown key
+// at path (e.g. "user_details.fristname" or "[2].emial"), resolving the enclosing
+// struct from root through the JSON names in the path.
+func suggestField(root reflect.Type, path string, cfg *config) (string, bool) {
+	typ := root
+	parts := strings.Split(strings.ReplaceAll(path, "[", ".["), ".")
+	for _, seg := range parts[:len(parts)-1] {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		switch {
+		case seg == "":
+		case strings.HasPrefix(seg, "["):
+			if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+				return "", false
+			}
+			typ = typ.Elem()
+		default:
+			fi, ok := jsonFieldLookup(typ)[seg]
+			if !ok {
+				return "", false
+			}
+			typ = fi.FieldType
+		}
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return "", false
+	}
+	key := parts[len(parts)-1]
+	if normalize := cfg.inputNormalizer(); normalize != nil {
+		key = normalize(key)
+	}
+	fi, ok := bestFieldMatch(key, cfg.fieldLookupEntry(typ), nil, cfg.FuzzyThreshold)
+	return fi.JSONName, ok
+}
+
+// unknownFieldsError is the strict mode error for unmatched input paths of a
+// value of type typ, with suggestions when fuzzy matching is enabled.
+func (c *config) unknownFieldsError(typ reflect.Type, unmatched []string) error {
+	msg := fmt.Sprintf("databridge: strict mode - unknown fields present: %v", unmatched)
+	if c.FuzzyThreshold > 0 {
+		var hints []string
+		for _, u := range unmatched {
+			if s, ok := suggestField(typ, u, c); ok {
+				hints = append(hints, fmt.Sprintf("%q: did you mean '%s'?", u, s))
+			}
+		}
+		if len(hints) > 0 {
+			msg += " (" + strings.Join(hints, "; ") + ")"
+		}
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,63 @@
+package databridge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeySimilarity(t *testing.T) {
+	if s := keySimilarity("fristname", "firstname"); s < 0.88 || s > 0.9 {
+		t.Fatalf("transposition similarity = %v", s)
+	}
+	if s := keySimilarity("email", "email"); s != 1 {
+		t.Fatalf("identical similarity = %v", s)
+	}
+	if s := keySimilarity("id", "zip"); s > 0.5 {
+		t.Fatalf("unrelated similarity = %v", s)
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	type Details struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+	}
+	type Person struct {
+		Details Details `json:"user_details"`
+		Email   string  `json:"email"`
+	}
+	in := `{"user_details":{"frist_name":"Ada","last_name":"Lovelace"},"emial":"ada@example.com","zzz":1}`
+
+	var p Person
+	if err := TransformToStructUniversal(in, &p, WithFuzzyMatch(0.8)); err != nil {
+		t.Fatalf("fuzzy transform failed: %v", err)
+	}
+	if p.Details.FirstName != "Ada" || p.Email != "ada@example.com" {
+		t.Fatalf("fuzzy transform = %+v", p)
+	}
+
+	// without the option typos stay unmatched
+	p = Person{}
+	if err := TransformToStructUniversal(in, &p); err != nil || p.Details.FirstName != "" {
+		t.Fatalf("typo matched without fuzzy option: %+v err=%v", p, err)
+	}
+
+	// an exact key wins; the near miss is not bound over it
+	p = Person{}
+	if err := TransformToStructUniversal(`{"email":"a@x.io","emial":"b@x.io"}`, &p, WithFuzzyMatch(0.8)); err != nil || p.Email != "a@x.io" {
+		t.Fatalf("fuzzy overrode exact key: %+v err=%v", p, err)
+	}
+
+	err := TransformToStructUniversal(in, &p, WithFuzzyMatch(0.8), WithStrict(true))
+	if err == nil {
+		t.Fatalf("expected strict error")
+	}
+	for _, want := range []string{`"emial": did you mean 'email'?`, `"user_details.fristname": did you mean 'first_name'?`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("strict error %q lacks %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), `"zzz": did you mean`) {
+		t.Fatalf("unexpected suggestion for zzz: %v", err)
+	}
+}
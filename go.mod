@@ -4,7 +4,10 @@ go 1.23.0
 
 toolchain go1.23.4
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	golang.org/x/text v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/kr/pretty v0.3.1 // indirect
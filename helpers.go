@@ -3,19 +3,25 @@ package databridge
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // numeric coercion helpers
 
-func stringToBestType(s string) interface{} {
+func stringToBestType(s string, cfg *config) interface{} {
 	if s == "" {
 		return ""
 	}
+	// locale-formatted numbers ("1.234,56") when a number format is configured
+	if cfg != nil && cfg.NumberFormat != nil {
+		if c, ok := canonicalNumber(s, cfg.NumberFormat, false); ok {
+			s = c
+		}
+	}
 	// int
 	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
 		return i
@@ -33,25 +39,27 @@ func stringToBestType(s string) interface{} {
 
 func coerceNumbersInMap(m map[string]interface{}, cfg *config) map[string]interface{} {
 	for k, v := range m {
-		switch vv := v.(type) {
-		case map[string]interface{}:
-			m[k] = coerceNumbersInMap(vv, cfg)
-		case []interface{}:
-			for i, e := range vv {
-				if em, ok := e.(map[string]interface{}); ok {
-					vv[i] = coerceNumbersInMap(em, cfg)
-				} else {
-					vv[i] = coerceNumberValue(e)
-				}
-			}
-			m[k] = vv
-		default:
-			m[k] = coerceNumberValue(vv)
-		}
+		m[k] = coerceValue(v, cfg)
 	}
 	return m
 }
 
+// coerceValue applies coerceNumberValue to v and to the values of the maps and
+// arrays it contains, at any depth.
+func coerceValue(v interface{}, cfg *config) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		return coerceNumbersInMap(vv, cfg)
+	case []interface{}:
+		for i, e := range vv {
+			vv[i] = coerceValue(e, cfg)
+		}
+		return vv
+	default:
+		return coerceNumberValue(vv)
+	}
+}
+
 func coerceNumberValue(v interface{}) interface{} {
 	switch n := v.(type) {
 	case float64:
@@ -59,6 +67,21 @@ func coerceNumberValue(v interface{}) interface{} {
 			return int64(n)
 		}
 		return n
+	case json.Number:
+		// JSON input is decoded with UseNumber so 64-bit IDs keep every digit
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+			return u
+		}
+		if strings.ContainsAny(n.String(), ".eE") {
+			if f, err := n.Float64(); err == nil {
+				return f
+			}
+		}
+		// integer beyond 64 bits: keep the literal, it re-encodes verbatim
+		return n
 	default:
 		return v
 	}
@@ -100,13 +123,20 @@ func bestEffortConvert(m map[string]interface{}) map[string]interface{} {
 
 // normalizeMapKeysDeep applies a key normalizer to all keys in the map recursively.
 // It preserves the original value shapes and recurses through maps and slices.
+// When keys collide, the result does not depend on map iteration order.
 func normalizeMapKeysDeep(m map[string]interface{}, normalizer func(string) string) map[string]interface{} {
 	if m == nil || normalizer == nil {
 		return m
 	}
 	out := make(map[string]interface{}, len(m))
+	from := make(map[string]string, len(m)) // normalized key -> input key kept
 	for k, v := range m {
 		nk := normalizer(k)
+		// keys that normalize alike: prefer the one already normalized, then the smallest
+		if prev, ok := from[nk]; ok && (prev == nk || (k != nk && prev < k)) {
+			continue
+		}
+		from[nk] = k
 		switch vv := v.(type) {
 		case map[string]interface{}:
 			out[nk] = normalizeMapKeysDeep(vv, normalizer)
@@ -127,184 +157,377 @@ func normalizeMapKeysDeep(m map[string]interface{}, normalizer func(string) stri
 	return out
 }
 
+// normalizeValueKeys applies normalizeMapKeysDeep to an object or to the objects
+// of an array; other values, and all values when normalizer is nil, are returned
+// unchanged.
+func normalizeValueKeys(v interface{}, normalizer func(string) string) interface{} {
+	if normalizer == nil {
+		return v
+	}
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		return normalizeMapKeysDeep(vv, normalizer)
+	case []interface{}:
+		out := make([]interface{}, len(vv))
+		for i, e := range vv {
+			out[i] = normalizeValueKeys(e, normalizer)
+		}
+		return out
+	}
+	return v
+}
+
 // --- Type-aware coercion based on target struct shape ---
 
 // coerceAccordingToType walks the input map and converts primitive values (strings, numbers)
 // into the types expected by the provided struct type. It handles nested structs and slices.
-func coerceAccordingToType(in map[string]interface{}, typ reflect.Type) map[string]interface{} {
+// path is the field path of the map within the overall document and prefixes error paths.
+func coerceAccordingToType(in map[string]interface{}, typ reflect.Type, cfg *config, path string) (map[string]interface{}, error) {
 	if in == nil {
-		return nil
+		return nil, nil
 	}
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
 	}
 	if typ.Kind() != reflect.Struct {
-		return in
+		return in, nil
 	}
 	// Build map: json field name -> reflect.Type
-	fields := buildFieldLookup(typ, nil) // use raw json tags/names (no normalization here)
+	fields := jsonFieldLookup(typ) // use raw json tags/names (no normalization here)
 	out := make(map[string]interface{}, len(in))
 	for k, v := range in {
 		if fi, ok := fields[k]; ok {
-			out[k] = coerceValueForType(v, fi.FieldType)
+			tag := fi.Tag
+			tag.Sensitive = cfg.sensitive(k, tag)
+			cv, err := coerceValueForType(v, fi.FieldType, tag, cfg, joinFieldPath(path, k))
+			if err != nil {
+				return nil, err
+			}
+			out[k] = cv
 		} else {
 			out[k] = v
 		}
 	}
-	return out
+	return out, nil
 }
 
-func coerceValueForType(v interface{}, t reflect.Type) interface{} {
+// joinFieldPath appends a field name to a dotted field path.
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func coerceValueForType(v interface{}, t reflect.Type, tag fieldTag, cfg *config, path string) (out interface{}, err error) {
+	if tag.Sensitive {
+		// registered first so the explain report below is still masked
+		leave := cfg.explain.redacting()
+		defer func() {
+			leave()
+			err = redactFieldError(err)
+		}()
+	}
+	if cfg.explain != nil {
+		in, ft := v, t
+		defer func() {
+			if err == nil {
+				cfg.explain.coerced(path, in, out, ft)
+			}
+		}()
+	}
+	// Sanitize strings first so converters see the cleaned value
+	if s, ok := v.(string); ok {
+		if p := cfg.stringPolicyFor(tag); p != nil {
+			ps, null, err := applyStringPolicy(s, t, p)
+			if err != nil {
+				return nil, &FieldError{Path: path, Err: err}
+			}
+			if null {
+				return nil, nil
+			}
+			v = ps
+		}
+	}
+	// Registered converters take precedence over the kind-based rules below
+	if cv, handled, err := convertWithRegistered(v, t, tag, cfg); handled {
+		if err != nil {
+			return nil, &FieldError{Path: path, Err: err}
+		}
+		return cv, nil
+	}
 	// Track pointer and unwrap
 	isPtr := false
 	if t.Kind() == reflect.Ptr {
 		isPtr = true
 		t = t.Elem()
 	}
-	// If original field is pointer and incoming value is an empty string, keep it nil
+	// If original field is pointer and incoming value is null or an empty string, keep it nil
 	if isPtr {
+		if v == nil {
+			return nil, nil
+		}
 		if s, ok := v.(string); ok && strings.TrimSpace(s) == "" {
-			return nil
+			return nil, nil
+		}
+	}
+	if t == durationType {
+		dv, err := coerceDuration(v)
+		if err != nil {
+			return nil, &FieldError{Path: path, Err: err}
 		}
+		return dv, nil
 	}
 	switch t.Kind() {
 	case reflect.String:
 		if v == nil {
-			return ""
-		}
-		switch x := v.(type) {
-		case string:
-			return x
-		case int64:
-			return strconv.FormatInt(x, 10)
-		case uint64:
-			return strconv.FormatUint(x, 10)
-		case float64:
-			// avoid trailing .0 for whole numbers
-			if x == float64(int64(x)) {
-				return strconv.FormatInt(int64(x), 10)
-			}
-			return strconv.FormatFloat(x, 'f', -1, 64)
-		case bool:
-			return strconv.FormatBool(x)
-		case map[string]interface{}:
-			// Prefer common key names when converting an object to string
-			if val, ok := x["value"]; ok {
-				return coerceValueForType(val, reflect.TypeOf(""))
-			}
-			if num, ok := x["number"]; ok {
-				return coerceValueForType(num, reflect.TypeOf(""))
-			}
-			if id, ok := x["id"]; ok {
-				return coerceValueForType(id, reflect.TypeOf(""))
-			}
-			if name, ok := x["name"]; ok {
-				return coerceValueForType(name, reflect.TypeOf(""))
-			}
-			// Try other common field names
-			if phone, ok := x["phone"]; ok {
-				return coerceValueForType(phone, reflect.TypeOf(""))
-			}
-			if ext, ok := x["ext"]; ok {
-				return coerceValueForType(ext, reflect.TypeOf(""))
-			}
-			// Fallback: JSON-encode the object for readable string representation
-			if jsonBytes, err := json.Marshal(x); err == nil {
-				return string(jsonBytes)
-			}
-			return fmt.Sprintf("%v", x)
-		default:
-			return fmt.Sprintf("%v", v)
+			return "", nil
 		}
+		return coerceToString(v), nil
 	case reflect.Bool:
 		switch x := v.(type) {
 		case string:
 			if b, err := strconv.ParseBool(strings.TrimSpace(x)); err == nil {
-				return b
+				return b, nil
+			}
+			if b, ok := parseBoolWord(x, cfg); ok {
+				return b, nil
 			}
 		}
-		return v
+		return v, nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		switch x := v.(type) {
-		case string:
-			if i, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64); err == nil {
-				// return as int64; json will fit into desired int size on unmarshal
-				return i
-			}
-		case float64:
-			return int64(x)
+		iv, err := coerceSigned(v, t.Bits(), cfg)
+		if err != nil {
+			return nil, &FieldError{Path: path, Err: err}
 		}
-		return v
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		switch x := v.(type) {
-		case string:
-			if u, err := strconv.ParseUint(strings.TrimSpace(x), 10, 64); err == nil {
-				return u
-			}
-		case float64:
-			if x < 0 {
-				return uint64(0)
-			}
-			return uint64(x)
+		return iv, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		uv, err := coerceUnsigned(v, t.Bits(), cfg)
+		if err != nil {
+			return nil, &FieldError{Path: path, Err: err}
 		}
-		return v
+		return uv, nil
 	case reflect.Float32, reflect.Float64:
-		switch x := v.(type) {
-		case string:
-			if f, err := strconv.ParseFloat(strings.TrimSpace(x), 64); err == nil {
-				return f
-			}
-		case int64:
-			return float64(x)
+		fv, err := coerceFloat(v, t.Bits(), cfg)
+		if err != nil {
+			return nil, &FieldError{Path: path, Err: err}
 		}
-		return v
+		return fv, nil
 	case reflect.Struct:
 		// Special-case time.Time
-		if t == reflect.TypeOf(time.Time{}) {
-			switch x := v.(type) {
-			case string:
-				if tt, ok := parseTimeFlexible(x); ok {
-					return tt
-				}
+		if t == timeType {
+			tv, err := coerceTime(v, tag, cfg)
+			if err != nil {
+				return nil, &FieldError{Path: path, Err: err}
 			}
-			return v
+			return tv, nil
 		}
 		if m, ok := v.(map[string]interface{}); ok {
-			return coerceAccordingToType(m, t)
+			return coerceAccordingToType(m, t, cfg, path)
 		}
-		return v
+		return v, nil
 	case reflect.Slice, reflect.Array:
 		// Expect []T
 		if arr, ok := v.([]interface{}); ok {
 			elemT := t.Elem()
 			out := make([]interface{}, len(arr))
 			for i := range arr {
-				out[i] = coerceValueForType(arr[i], elemT)
+				ev, err := coerceValueForType(arr[i], elemT, tag, cfg, fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return nil, err
+				}
+				out[i] = ev
 			}
-			return out
+			return out, nil
 		}
-		return v
+		return v, nil
 	default:
-		return v
+		return v, nil
 	}
 }
 
-// parseTimeFlexible tries several common timestamp formats including RFC3339.
-func parseTimeFlexible(s string) (time.Time, bool) {
+// numericValue extracts a number from v. isInt reports whether it was read
+// exactly as an int64 (i) or uint64 (u, with isUint); otherwise f holds it.
+// ok is false when v is not numeric (e.g. a non-numeric string). Strings are
+// read in the configured NumberFormat, if any.
+func numericValue(v interface{}, cfg *config) (i int64, u uint64, f float64, isInt, isUint, ok bool) {
+	switch x := v.(type) {
+	case int64:
+		return x, 0, 0, true, false, true
+	case uint64:
+		return 0, x, 0, false, true, true
+	case float64:
+		return 0, 0, x, false, false, true
+	case json.Number:
+		v = x.String()
+	}
+	s, isStr := v.(string)
+	if !isStr {
+		return 0, 0, 0, false, false, false
+	}
 	s = strings.TrimSpace(s)
-	if s == "" {
-		return time.Time{}, false
+	if cfg.NumberFormat != nil {
+		if c, ok := canonicalNumber(s, cfg.NumberFormat, true); ok {
+			s = c
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, 0, 0, true, false, true
+	}
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return 0, n, 0, false, true, true
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return 0, 0, n, false, false, true
+	}
+	return 0, 0, 0, false, false, false
+}
+
+// truncFloat handles a fractional value headed for an integer field: strict
+// numbers reject it, otherwise it is truncated toward zero.
+func truncFloat(f float64, cfg *config) (float64, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%v is not a finite number", f)
+	}
+	if f != math.Trunc(f) {
+		if cfg.StrictNumbers {
+			return 0, fmt.Errorf("%v has a fractional part", f)
+		}
+		f = math.Trunc(f)
+	}
+	return f, nil
+}
+
+// coerceSigned converts v for a signed integer field of the given bit size.
+// Out-of-range values are always rejected since they cannot be decoded.
+func coerceSigned(v interface{}, bits int, cfg *config) (interface{}, error) {
+	i, u, f, isInt, isUint, ok := numericValue(v, cfg)
+	if !ok {
+		return v, nil
+	}
+	switch {
+	case isUint:
+		if u > math.MaxInt64 {
+			return nil, fmt.Errorf("%d overflows int%d", u, bits)
+		}
+		i = int64(u)
+	case !isInt:
+		tf, err := truncFloat(f, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if tf < math.MinInt64 || tf >= math.MaxInt64 {
+			return nil, fmt.Errorf("%v overflows int%d", f, bits)
+		}
+		i = int64(tf)
+	}
+	min, max := int64(-1)<<(bits-1), int64(1)<<(bits-1)-1
+	if i < min || i > max {
+		return nil, fmt.Errorf("%d overflows int%d", i, bits)
+	}
+	// return as int64; json will fit into desired int size on unmarshal
+	return i, nil
+}
+
+// coerceUnsigned converts v for an unsigned integer field of the given bit size.
+// Negative values are rejected in strict number mode and clamped to 0 otherwise.
+func coerceUnsigned(v interface{}, bits int, cfg *config) (interface{}, error) {
+	i, u, f, isInt, isUint, ok := numericValue(v, cfg)
+	if !ok {
+		return v, nil
+	}
+	switch {
+	case isInt:
+		if i < 0 {
+			if cfg.StrictNumbers {
+				return nil, fmt.Errorf("negative value %d for uint%d", i, bits)
+			}
+			i = 0
+		}
+		u = uint64(i)
+	case !isUint:
+		tf, err := truncFloat(f, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if tf < 0 {
+			if cfg.StrictNumbers {
+				return nil, fmt.Errorf("negative value %v for uint%d", f, bits)
+			}
+			tf = 0
+		}
+		if tf >= math.MaxUint64 {
+			return nil, fmt.Errorf("%v overflows uint%d", f, bits)
+		}
+		u = uint64(tf)
+	}
+	if bits < 64 && u > uint64(1)<<bits-1 {
+		return nil, fmt.Errorf("%d overflows uint%d", u, bits)
+	}
+	return u, nil
+}
+
+// coerceFloat converts v for a float field of the given bit size. Strict number
+// mode rejects integers that float64 cannot represent exactly.
+func coerceFloat(v interface{}, bits int, cfg *config) (interface{}, error) {
+	i, u, f, isInt, isUint, ok := numericValue(v, cfg)
+	if !ok {
+		return v, nil
+	}
+	switch {
+	case isInt:
+		f = float64(i)
+		if cfg.StrictNumbers && (f >= math.MaxInt64 || int64(f) != i) {
+			return nil, fmt.Errorf("%d cannot be represented exactly as float%d", i, bits)
+		}
+	case isUint:
+		f = float64(u)
+		if cfg.StrictNumbers && (f >= math.MaxUint64 || uint64(f) != u) {
+			return nil, fmt.Errorf("%d cannot be represented exactly as float%d", u, bits)
+		}
 	}
-	formats := []string{
-		time.RFC3339Nano,
-		time.RFC3339,
-		"2006-01-02 15:04:05",
-		"2006-01-02",
+	if bits == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+		return nil, fmt.Errorf("%v overflows float32", f)
 	}
-	for _, f := range formats {
-		if t, err := time.Parse(f, s); err == nil {
-			return t, true
+	return f, nil
+}
+
+// coerceToString renders a decoded value as a string, preferring common key
+// names (value, number, id, ...) when the value is an object.
+func coerceToString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case uint64:
+		return strconv.FormatUint(x, 10)
+	case json.Number:
+		return x.String()
+	case float64:
+		// avoid trailing .0 for whole numbers
+		if x == float64(int64(x)) {
+			return strconv.FormatInt(int64(x), 10)
+		}
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case map[string]interface{}:
+		// Prefer common key names when converting an object to string
+		for _, k := range []string{"value", "number", "id", "name", "phone", "ext"} {
+			if val, ok := x[k]; ok {
+				if val == nil {
+					return ""
+				}
+				return coerceToString(val)
+			}
 		}
+		// Fallback: JSON-encode the object for readable string representation
+		if jsonBytes, err := json.Marshal(x); err == nil {
+			return string(jsonBytes)
+		}
+		return fmt.Sprintf("%v", x)
+	default:
+		return fmt.Sprintf("%v", v)
 	}
-	return time.Time{}, false
 }
@@ -0,0 +1,215 @@
+package databridge
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// BeforeBinder is implemented by target types that adjust their raw input before
+// it is converted and decoded. raw holds the object headed for the value, keyed
+// by the type's JSON field names (unknown keys are kept); changes to it are
+// decoded. The method is called on a zero value of the type, and on nested struct
+// fields and slice elements as well as the top-level target, outermost first.
+type BeforeBinder interface {
+	BeforeBind(raw map[string]interface{}) error
+}
+
+// AfterBinder is implemented by target types that finalize themselves after
+// decoding, e.g. to derive computed fields or validate. It is called on every
+// decoded value of the type, nested values before the values containing them.
+type AfterBinder interface {
+	AfterBind() error
+}
+
+// BeforeBinderContext is BeforeBinder receiving the context of TransformContext
+// (context.Background() for the other entry points). It is called instead of
+// BeforeBind when a type implements both.
+type BeforeBinderContext interface {
+	BeforeBindContext(ctx context.Context, raw map[string]interface{}) error
+}
+
+// AfterBinderContext is AfterBinder receiving the context of TransformContext.
+// It is called instead of AfterBind when a type implements both.
+type AfterBinderContext interface {
+	AfterBindContext(ctx context.Context) error
+}
+
+var (
+	beforeBinderType        = reflect.TypeOf((*BeforeBinder)(nil)).Elem()
+	afterBinderType         = reflect.TypeOf((*AfterBinder)(nil)).Elem()
+	beforeBinderContextType = reflect.TypeOf((*BeforeBinderContext)(nil)).Elem()
+	afterBinderContextType  = reflect.TypeOf((*AfterBinderContext)(nil)).Elem()
+)
+
+// typeFeature is a property looked up across the types reachable from a target.
+type typeFeature int
+
+const (
+	// featureMapping: path, str or conv tags or BeforeBind hooks, which need the
+	// mapping and coercion phases
+	featureMapping typeFeature = iota
+	featureBeforeBind
+	featureAfterBind
+)
+
+type typeFeatureKey struct {
+	t reflect.Type
+	f typeFeature
+}
+
+var typeFeatureCache sync.Map // typeFeatureKey -> bool
+
+// typeHas reports whether typ, or a struct reachable through its fields, slices,
+// arrays, maps or pointers, has feature f.
+func typeHas(typ reflect.Type, f typeFeature) bool {
+	key := typeFeatureKey{typ, f}
+	if cached, ok := typeFeatureCache.Load(key); ok {
+		return cached.(bool)
+	}
+	found := typeHasWalk(typ, f, map[reflect.Type]bool{})
+	typeFeatureCache.Store(key, found)
+	return found
+}
+
+func typeHasWalk(typ reflect.Type, f typeFeature, seen map[reflect.Type]bool) bool {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return typeHasWalk(typ.Elem(), f, seen)
+	case reflect.Struct:
+		if seen[typ] {
+			return false
+		}
+		seen[typ] = true
+		ptr := reflect.PtrTo(typ)
+		switch f {
+		case featureMapping, featureBeforeBind:
+			if ptr.Implements(beforeBinderType) || ptr.Implements(beforeBinderContextType) {
+				return true
+			}
+		case featureAfterBind:
+			if ptr.Implements(afterBinderType) || ptr.Implements(afterBinderContextType) {
+				return true
+			}
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			if f == featureMapping {
+				if tag := parseFieldTag(sf.Tag.Get("databridge")); tag.Path != "" || tag.Str != nil || tag.Conv != "" {
+					return true
+				}
+			}
+			if typeHasWalk(sf.Type, f, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runBeforeBind calls BeforeBind for typ and the nested struct values of m, which
+// is keyed by JSON field names.
+func runBeforeBind(ctx context.Context, m map[string]interface{}, typ reflect.Type, path string) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || !typeHas(typ, featureBeforeBind) {
+		return nil
+	}
+	var err error
+	switch bb := reflect.New(typ).Interface().(type) {
+	case BeforeBinderContext:
+		err = bb.BeforeBindContext(ctx, m)
+	case BeforeBinder:
+		err = bb.BeforeBind(m)
+	}
+	if err != nil {
+		return hookError(path, "BeforeBind", err)
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		name, ok := jsonFieldName(sf)
+		if !ok {
+			continue
+		}
+		if err := runBeforeBindValue(ctx, m[name], sf.Type, joinFieldPath(path, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runBeforeBindValue(ctx context.Context, v interface{}, typ reflect.Type, path string) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return runBeforeBind(ctx, x, typ, path)
+	case []interface{}:
+		if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+			return nil
+		}
+		for i, e := range x {
+			if err := runBeforeBindValue(ctx, e, typ.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runAfterBind calls AfterBind on v and every nested value implementing
+// AfterBinder, innermost first. v must be addressable.
+func runAfterBind(ctx context.Context, v reflect.Value, path string) error {
+	if !typeHas(v.Type(), featureAfterBind) {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return runAfterBind(ctx, v.Elem(), path)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := runAfterBind(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := jsonFieldName(t.Field(i))
+			if !ok {
+				continue
+			}
+			if err := runAfterBind(ctx, v.Field(i), joinFieldPath(path, name)); err != nil {
+				return err
+			}
+		}
+		var err error
+		switch ab := v.Addr().Interface().(type) {
+		case AfterBinderContext:
+			err = ab.AfterBindContext(ctx)
+		case AfterBinder:
+			err = ab.AfterBind()
+		}
+		if err != nil {
+			return hookError(path, "AfterBind", err)
+		}
+	}
+	return nil
+}
+
+// hookError wraps a hook failure: a *FieldError for nested values, a plain
+// wrapped error for the top-level target.
+func hookError(path, hook string, err error) error {
+	if path == "" {
+		return fmt.Errorf("databridge: %s: %w", hook, err)
+	}
+	return &FieldError{Path: path, Err: fmt.Errorf("%s: %w", hook, err)}
+}
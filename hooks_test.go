@@ -0,0 +1,70 @@
+package databridge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type hookLine struct {
+	SKU   string `json:"sku"`
+	Qty   int    `json:"qty"`
+	Total int    `json:"-"`
+}
+
+func (l *hookLine) BeforeBind(raw map[string]interface{}) error {
+	if s, ok := raw["sku"].(string); ok {
+		raw["sku"] = strings.ToUpper(strings.TrimSpace(s))
+	}
+	return nil
+}
+
+func (l *hookLine) AfterBind() error {
+	if l.Qty < 0 {
+		return errors.New("negative quantity")
+	}
+	l.Total = l.Qty * 10
+	return nil
+}
+
+type hookOrder struct {
+	ID    string     `json:"id"`
+	Lines []hookLine `json:"lines"`
+	Sum   int        `json:"-"`
+}
+
+func (o *hookOrder) AfterBind() error {
+	// nested values are finalized first
+	for _, l := range o.Lines {
+		o.Sum += l.Total
+	}
+	return nil
+}
+
+func TestBindHooks(t *testing.T) {
+	var o hookOrder
+	if err := TransformToStructUniversal(`{"id":"o1","lines":[{"sku":" ab ","qty":"2"},{"sku":"cd","qty":1}]}`, &o); err != nil {
+		t.Fatalf("hooks transform failed: %v", err)
+	}
+	if o.Lines[0].SKU != "AB" || o.Lines[0].Total != 20 || o.Sum != 30 {
+		t.Fatalf("hooks not applied: %+v", o)
+	}
+
+	// rows and the fast path run the hooks too
+	var lines []hookLine
+	if err := TransformToStructUniversal(`[{"sku":"x","qty":3}]`, &lines, WithKeyNormalization(false)); err != nil {
+		t.Fatalf("hooks rows failed: %v", err)
+	}
+	if lines[0].SKU != "X" || lines[0].Total != 30 {
+		t.Fatalf("hooks not applied to rows: %+v", lines)
+	}
+}
+
+func TestBindHookErrors(t *testing.T) {
+	var o hookOrder
+	err := TransformToStructUniversal(`{"lines":[{"qty":1},{"qty":-1}]}`, &o)
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "lines[1]" || !strings.Contains(err.Error(), "AfterBind: negative quantity") {
+		t.Fatalf("expected AfterBind FieldError at lines[1], got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package databridge
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// KeyMatching selects how input keys are matched to struct fields.
+type KeyMatching int
+
+const (
+	// MatchASCII lowercases ASCII letters and drops every other character except
+	// digits, so "User-Name", "user_name" and "username" all match (default).
+	MatchASCII KeyMatching = iota
+	// MatchUnicodeFold case-folds, strips diacritics and drops everything but
+	// letters and digits in any script: "Prénom" matches "prenom" and "ИМЯ" "имя".
+	MatchUnicodeFold
+	// MatchExact requires keys to equal a field's JSON name or Go name.
+	MatchExact
+	// MatchCaseStyle converts camelCase, PascalCase, snake_case and kebab-case to
+	// the same words, so "userName" matches "user_name" but not "username".
+	MatchCaseStyle
+)
+
+// WithKeyMatching selects a built-in key matching strategy, replacing any
+// normalizer set with WithKeyNormalizer. Each strategy keeps its own cached
+// field lookups.
+func WithKeyMatching(m KeyMatching) Option {
+	return func(c *config) {
+		switch m {
+		case MatchUnicodeFold:
+			c.KeyNormalizer, c.keyMatcherID = unicodeFoldNormalizer, "unicode"
+		case MatchExact:
+			c.KeyNormalizer, c.keyMatcherID = exactNormalizer, "exact"
+		case MatchCaseStyle:
+			c.KeyNormalizer, c.keyMatcherID = caseStyleNormalizer, "casestyle"
+		default:
+			c.KeyNormalizer, c.keyMatcherID = defaultNormalizer, "ascii"
+		}
+	}
+}
+
+func exactNormalizer(s string) string { return s }
+
+var caseFolder = cases.Fold()
+
+// unicodeFoldNormalizer case-folds s, strips combining marks after canonical
+// decomposition and keeps only letters and digits.
+func unicodeFoldNormalizer(s string) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return defaultNormalizer(s)
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range norm.NFD.String(caseFolder.String(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// caseStyleNormalizer renders s as lower snake_case words: "userID", "UserId",
+// "user-id" and "USER_ID" all become "user_id", and "HTTPServer" "http_server".
+func caseStyleNormalizer(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	rs := []rune(s)
+	sep := false // a separator is pending before the next word
+	for i, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			sep = b.Len() > 0
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 && b.Len() > 0 {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sep = true
+			}
+		}
+		if sep {
+			b.WriteByte('_')
+			sep = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
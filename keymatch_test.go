@@ -0,0 +1,76 @@
+package databridge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyNormalizers(t *testing.T) {
+	cases := []struct {
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{unicodeFoldNormalizer, "Prénom", "prenom"},
+		{unicodeFoldNormalizer, "ИМЯ_Пользователя", "имяпользователя"},
+		{unicodeFoldNormalizer, "Straße", "strasse"},
+		{unicodeFoldNormalizer, "user_name", "username"},
+		{caseStyleNormalizer, "userName", "user_name"},
+		{caseStyleNormalizer, "UserID", "user_id"},
+		{caseStyleNormalizer, "user-id", "user_id"},
+		{caseStyleNormalizer, "USER_ID", "user_id"},
+		{caseStyleNormalizer, "HTTPServer", "http_server"},
+		{caseStyleNormalizer, "address2City", "address2_city"},
+	}
+	for _, c := range cases {
+		if got := c.fn(c.in); got != c.want {
+			t.Errorf("normalize(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestKeyMatchingStrategies(t *testing.T) {
+	type Person struct {
+		FirstName string `json:"prénom"`
+		UserName  string `json:"user_name"`
+		City      string `json:"город"`
+	}
+	var p Person
+	if err := TransformToStructUniversal(`{"Prenom":"Ana","USERNAME":"ana1","ГОРОД":"Москва"}`, &p, WithKeyMatching(MatchUnicodeFold)); err != nil {
+		t.Fatalf("unicode fold failed: %v", err)
+	}
+	if p != (Person{FirstName: "Ana", UserName: "ana1", City: "Москва"}) {
+		t.Fatalf("unicode fold = %+v", p)
+	}
+
+	p = Person{}
+	if err := TransformToStructUniversal(`{"userName":"a","username":"b"}`, &p, WithKeyMatching(MatchCaseStyle), WithStrict(true)); err == nil || !strings.Contains(err.Error(), "username") {
+		t.Fatalf("case style should match userName only, got %+v err=%v", p, err)
+	}
+
+	p = Person{}
+	err := TransformToStructUniversal(`{"user_name":"a","USER_NAME":"z"}`, &p, WithKeyMatching(MatchExact), WithStrict(true))
+	if err == nil || !strings.Contains(err.Error(), "USER_NAME") {
+		t.Fatalf("exact should reject USER_NAME, got %+v err=%v", p, err)
+	}
+}
+
+func TestKeyMatchingCacheIsolation(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+	}
+	var it Item
+	// warm the default (ASCII) cache, then use a normalizer that must not reuse it
+	if err := TransformToStructUniversal(`{"NAME":"a"}`, &it); err != nil || it.Name != "a" {
+		t.Fatalf("default = %+v err=%v", it, err)
+	}
+	suffix := func(s string) string { return strings.ToLower(s) + "_x" }
+	it = Item{}
+	if err := TransformToStructUniversal(`{"NAME":"b"}`, &it, WithKeyNormalizer(suffix)); err != nil || it.Name != "b" {
+		t.Fatalf("custom normalizer = %+v err=%v", it, err)
+	}
+	it = Item{}
+	if err := TransformToStructUniversal(`{"Name":"c"}`, &it, WithKeyMatching(MatchExact), WithStrict(true)); err != nil || it.Name != "c" {
+		t.Fatalf("exact = %+v err=%v", it, err)
+	}
+}
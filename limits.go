@@ -0,0 +1,300 @@
+package databridge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+var ErrLimitExceeded = errors.New("databridge: limit exceeded")
+
+// LimitError is returned when input exceeds a limit set with WithMaxBytes,
+// WithMaxDepth, WithMaxKeys, WithMaxArrayLen or WithMaxCSVRows. It wraps
+// ErrLimitExceeded.
+type LimitError struct {
+	Limit string // "bytes", "depth", "keys", "array length" or "csv rows"
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s > %d", ErrLimitExceeded, e.Limit, e.Max)
+}
+
+func (e *LimitError) Unwrap() error { return ErrLimitExceeded }
+
+// WithMaxBytes limits the size of string, []byte and io.Reader input; readers
+// are not read past the limit. 0 means unlimited (the default) for this and the
+// other limits.
+func WithMaxBytes(n int64) Option {
+	return func(c *config) { c.MaxBytes = n }
+}
+
+// WithMaxDepth limits the nesting depth of objects and arrays; a flat object has
+// depth 1. Deeper input is rejected before it reaches the recursive mapping and
+// coercion phases.
+func WithMaxDepth(n int) Option {
+	return func(c *config) { c.MaxDepth = n }
+}
+
+// WithMaxKeys limits the number of keys in any one object, including form fields
+// and CSV columns.
+func WithMaxKeys(n int) Option {
+	return func(c *config) { c.MaxKeys = n }
+}
+
+// WithMaxArrayLen limits the length of any array, including a top-level JSON
+// array of records. CSV rows are limited by WithMaxCSVRows instead.
+func WithMaxArrayLen(n int) Option {
+	return func(c *config) { c.MaxArrayLen = n }
+}
+
+// WithMaxCSVRows limits the number of CSV data rows (the header is not counted).
+// Rows are read one at a time, so parsing stops at the first row over the limit.
+func WithMaxCSVRows(n int) Option {
+	return func(c *config) { c.MaxCSVRows = n }
+}
+
+// hasShapeLimits reports whether depth, key or array limits are set.
+func (c *config) hasShapeLimits() bool {
+	return c.MaxDepth > 0 || c.MaxKeys > 0 || c.MaxArrayLen > 0
+}
+
+// scanJSONLimits checks the shape limits against raw JSON without decoding it, so
+// hostile documents are rejected before the decoder recurses into them.
+func (c *config) scanJSONLimits(b []byte) error {
+	if !c.hasShapeLimits() {
+		return nil
+	}
+	type frame struct {
+		obj    bool
+		commas int
+	}
+	var (
+		stack      []frame
+		inStr, esc bool
+	)
+	for _, ch := range b {
+		if inStr {
+			switch {
+			case esc:
+				esc = false
+			case ch == '\\':
+				esc = true
+			case ch == '"':
+				inStr = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inStr = true
+		case '{', '[':
+			stack = append(stack, frame{obj: ch == '{'})
+			if c.MaxDepth > 0 && len(stack) > c.MaxDepth {
+				return &LimitError{Limit: "depth", Max: int64(c.MaxDepth)}
+			}
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case ',':
+			if len(stack) == 0 {
+				continue
+			}
+			top := &stack[len(stack)-1]
+			top.commas++
+			if err := c.checkCount(top.obj, top.commas+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkCount checks the number of keys of an object or elements of an array.
+func (c *config) checkCount(obj bool, n int) error {
+	if obj && c.MaxKeys > 0 && n > c.MaxKeys {
+		return &LimitError{Limit: "keys", Max: int64(c.MaxKeys)}
+	}
+	if !obj && c.MaxArrayLen > 0 && n > c.MaxArrayLen {
+		return &LimitError{Limit: "array length", Max: int64(c.MaxArrayLen)}
+	}
+	return nil
+}
+
+// checkFormLimits checks the shape limits against form values before dotted keys
+// are expanded into nested objects: "a.b" sits at depth 2, and a repeated key
+// holds an array one level deeper.
+func (c *config) checkFormLimits(vals url.Values) error {
+	if !c.hasShapeLimits() {
+		return nil
+	}
+	type node map[string]node
+	root := node{}
+	for k, arr := range vals {
+		parts := strings.Split(k, ".")
+		depth := len(parts)
+		if len(arr) > 1 {
+			depth++
+			if err := c.checkCount(false, len(arr)); err != nil {
+				return err
+			}
+		}
+		if err := c.checkDepth(depth); err != nil {
+			return err
+		}
+		n := root
+		for _, p := range parts {
+			child, ok := n[p]
+			if !ok {
+				child = node{}
+				n[p] = child
+				if err := c.checkCount(true, len(n)); err != nil {
+					return err
+				}
+			}
+			n = child
+		}
+	}
+	return nil
+}
+
+// checkYAMLLimits checks the shape limits against a parsed YAML node tree before
+// it is decoded. Aliases are not followed; yaml.v3 bounds their expansion and the
+// decoded value is checked again by checkParsedLimits.
+func (c *config) checkYAMLLimits(n *yaml.Node, depth int) error {
+	if !c.hasShapeLimits() {
+		return nil
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, e := range n.Content {
+			if err := c.checkYAMLLimits(e, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.MappingNode:
+		depth++
+		if err := c.checkDepth(depth); err != nil {
+			return err
+		}
+		if err := c.checkCount(true, len(n.Content)/2); err != nil {
+			return err
+		}
+	case yaml.SequenceNode:
+		depth++
+		if err := c.checkDepth(depth); err != nil {
+			return err
+		}
+		if err := c.checkCount(false, len(n.Content)); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	for _, e := range n.Content {
+		if err := c.checkYAMLLimits(e, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanXMLLimits checks the shape limits against XML tokens before the document
+// is unmarshalled: distinct child element names count as an element's keys and a
+// repeated name as an array. Malformed input is left to xml.Unmarshal to reject.
+func (c *config) scanXMLLimits(b []byte) error {
+	if !c.hasShapeLimits() || len(b) == 0 || b[0] != '<' {
+		return nil
+	}
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	var stack []map[string]int // child name counts of each open element
+	for {
+		tok, err := dec.RawToken()
+		if err != nil {
+			return nil
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if len(stack) > 0 {
+				names := stack[len(stack)-1]
+				names[t.Name.Local]++
+				if err := c.checkCount(true, len(names)); err != nil {
+					return err
+				}
+				if err := c.checkCount(false, names[t.Name.Local]); err != nil {
+					return err
+				}
+			}
+			stack = append(stack, map[string]int{})
+			if err := c.checkDepth(len(stack)); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+// checkParsedLimits checks the shape limits against parsed non-JSON input (forms,
+// YAML, XML, CSV rows and structured values). Records of rows sit at depth 2, as
+// in a JSON array.
+func (c *config) checkParsedLimits(m map[string]interface{}, rows []map[string]interface{}) error {
+	if !c.hasShapeLimits() {
+		return nil
+	}
+	if m != nil {
+		return c.checkValueLimits(m, 1)
+	}
+	for _, r := range rows {
+		if err := c.checkValueLimits(r, 2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *config) checkValueLimits(v interface{}, depth int) error {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		if err := c.checkDepth(depth); err != nil {
+			return err
+		}
+		if err := c.checkCount(true, len(x)); err != nil {
+			return err
+		}
+		for _, e := range x {
+			if err := c.checkValueLimits(e, depth+1); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if err := c.checkDepth(depth); err != nil {
+			return err
+		}
+		if err := c.checkCount(false, len(x)); err != nil {
+			return err
+		}
+		for _, e := range x {
+			if err := c.checkValueLimits(e, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *config) checkDepth(depth int) error {
+	if c.MaxDepth > 0 && depth > c.MaxDepth {
+		return &LimitError{Limit: "depth", Max: int64(c.MaxDepth)}
+	}
+	return nil
+}
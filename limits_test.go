@@ -0,0 +1,93 @@
+package databridge
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	deep := strings.Repeat(`{"a":`, 10000) + "1" + strings.Repeat("}", 10000)
+	cases := []struct {
+		name  string
+		input interface{}
+		opt   Option
+		limit string
+	}{
+		{"bytes", `{"name":"Ada"}`, WithMaxBytes(8), "bytes"},
+		{"reader bytes", strings.NewReader(strings.Repeat("x", 1<<20)), WithMaxBytes(1024), "bytes"},
+		{"json depth", deep, WithMaxDepth(32), "depth"},
+		{"array depth", `{"a":[[[1]]]}`, WithMaxDepth(3), "depth"},
+		{"json keys", `{"a":1,"b":2,"c":"x,y"}`, WithMaxKeys(2), "keys"},
+		{"json array", `{"tags":[1,2,3,4]}`, WithMaxArrayLen(3), "array length"},
+		{"top-level array", `[{"a":1},{"a":2}]`, WithMaxArrayLen(1), "array length"},
+		{"form depth", "a.b.c.d=1", WithMaxDepth(3), "depth"},
+		{"form keys", url.Values{"a": {"1"}, "b": {"2"}, "c": {"3"}}, WithMaxKeys(2), "keys"},
+		{"map depth", map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{}}}, WithMaxDepth(2), "depth"},
+		{"csv rows", "name,age\na,1\nb,2\nc,3\n", WithMaxCSVRows(2), "csv rows"},
+		{"csv columns", "a,b,c\n1,2,3\n", WithMaxKeys(2), "keys"},
+	}
+	for _, tc := range cases {
+		var out []map[string]interface{}
+		err := TransformToStructUniversal(tc.input, &out, tc.opt)
+		var le *LimitError
+		if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &le) || le.Limit != tc.limit {
+			t.Errorf("%s: err = %v, want %s limit", tc.name, err, tc.limit)
+		}
+	}
+
+	// input at the limits is accepted; brackets and commas inside strings don't count
+	type Doc struct {
+		Name string `json:"name"`
+		Tags []int  `json:"tags"`
+	}
+	var d Doc
+	in := `{"name":"[{,,}]","tags":[1,2,3]}`
+	opts := []Option{WithMaxBytes(int64(len(in))), WithMaxDepth(2), WithMaxKeys(2), WithMaxArrayLen(3)}
+	if err := TransformToStructUniversal(in, &d, opts...); err != nil || d.Name != "[{,,}]" || len(d.Tags) != 3 {
+		t.Fatalf("within limits: %+v, %v", d, err)
+	}
+	// the fast path is guarded as well
+	if _, err := FromJSONString[Doc](`{"tags":[1,2,3,4]}`, WithMaxArrayLen(3)); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("fast path err = %v", err)
+	}
+	var rows []map[string]interface{}
+	if err := TransformToStructUniversal("name,age\na,1\nb,2\n", &rows, WithMaxCSVRows(2)); err != nil || len(rows) != 2 {
+		t.Fatalf("csv within limit: %v, %v", rows, err)
+	}
+}
+
+func TestLimitsCheckedBeforeDecode(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		cfg   config
+		limit string
+	}{
+		{"form array", "tag=a&tag=b&tag=c", config{MaxArrayLen: 2}, "array length"},
+		{"form nested keys", "a.x=1&a.y=2&a.z=3", config{MaxKeys: 2}, "keys"},
+		{"yaml depth", "a:\n  b:\n    c: 1\n", config{EnableYAML: true, MaxDepth: 2}, "depth"},
+		{"yaml keys", "a: 1\nb: 2\nc: 3\n", config{EnableYAML: true, MaxKeys: 2}, "keys"},
+		{"yaml sequence", "tags: [1, 2, 3]\n", config{EnableYAML: true, MaxArrayLen: 2}, "array length"},
+		{"xml depth", "<a><b><c>1</c></b></a>", config{MaxDepth: 2}, "depth"},
+		{"xml keys", "<a><x/><y/><z/></a>", config{MaxKeys: 2}, "keys"},
+		{"xml repeated", "<a><i/><i/><i/></a>", config{MaxArrayLen: 2}, "array length"},
+	}
+	for _, tc := range cases {
+		_, _, _, err := parseFormats([]byte(tc.input), &tc.cfg)
+		var le *LimitError
+		if !errors.As(err, &le) || le.Limit != tc.limit {
+			t.Errorf("%s: err = %v, want %s limit", tc.name, err, tc.limit)
+		}
+	}
+
+	// at the limits the values parse as before
+	cfg := config{EnableYAML: true, MaxDepth: 2, MaxKeys: 2, MaxArrayLen: 2}
+	if format, m, _, err := parseFormats([]byte("a: 1\nb: [1, 2]\n"), &cfg); err != nil || format != "yaml" || len(m) != 2 {
+		t.Fatalf("yaml within limits: %s %v %v", format, m, err)
+	}
+	if format, m, _, err := parseFormats([]byte("a.x=1&a.y=2&t=1&t=2"), &cfg); err != nil || format != "form" || len(m) != 2 {
+		t.Fatalf("form within limits: %s %v %v", format, m, err)
+	}
+}
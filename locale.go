@@ -0,0 +1,194 @@
+package databridge
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NumberFormat describes how numbers are written in string input, e.g. the
+// "1.234,56" of German spreadsheets.
+type NumberFormat struct {
+	// Decimal is the decimal separator ('.' when zero).
+	Decimal rune
+	// Grouping lists the accepted thousands separators, e.g. "." or " \u00a0".
+	Grouping string
+}
+
+// locales maps language tags to their number format and boolean words.
+// Lookups fall back from "de-CH" to "de".
+var locales = map[string]struct {
+	nf     NumberFormat
+	truthy []string
+	falsy  []string
+}{
+	"en":    {NumberFormat{Decimal: '.', Grouping: ","}, nil, nil},
+	"de":    {NumberFormat{Decimal: ',', Grouping: "."}, []string{"ja", "j"}, []string{"nein"}},
+	"de-ch": {NumberFormat{Decimal: '.', Grouping: "'’"}, []string{"ja", "j"}, []string{"nein"}},
+	"nl":    {NumberFormat{Decimal: ',', Grouping: "."}, []string{"ja", "j"}, []string{"nee"}},
+	"es":    {NumberFormat{Decimal: ',', Grouping: "."}, []string{"sí", "si", "s"}, []string{"no"}},
+	"it":    {NumberFormat{Decimal: ',', Grouping: "."}, []string{"sì", "si", "s"}, []string{"no"}},
+	"pt":    {NumberFormat{Decimal: ',', Grouping: "."}, []string{"sim", "s"}, []string{"não", "nao"}},
+	"fr":    {NumberFormat{Decimal: ',', Grouping: " \u00a0\u202f"}, []string{"oui", "o"}, []string{"non"}},
+	"ru":    {NumberFormat{Decimal: ',', Grouping: " \u00a0\u202f"}, []string{"да", "д"}, []string{"нет"}},
+	"pl":    {NumberFormat{Decimal: ',', Grouping: " \u00a0\u202f"}, []string{"tak", "t"}, []string{"nie"}},
+	"sv":    {NumberFormat{Decimal: ',', Grouping: " \u00a0\u202f"}, []string{"ja", "j"}, []string{"nej"}},
+}
+
+// defaultTrueWords and defaultFalseWords are accepted for bool fields in addition
+// to the strconv.ParseBool forms (1, t, true, ...).
+var (
+	defaultTrueWords  = []string{"yes", "y", "on"}
+	defaultFalseWords = []string{"no", "n", "off"}
+)
+
+// WithNumberFormat parses numeric strings with the given decimal and grouping
+// separators. When decoding into numeric fields, currency symbols or codes
+// ("$", "€", "EUR") and a percent sign are stripped, so "$1,200.00" becomes
+// 1200 and "45%" becomes 45.
+func WithNumberFormat(nf NumberFormat) Option {
+	return func(c *config) {
+		if nf.Decimal == 0 {
+			nf.Decimal = '.'
+		}
+		c.NumberFormat = &nf
+	}
+}
+
+// WithLocale applies the number format and boolean words of a language tag such
+// as "de", "fr-FR" or "de-CH". Unknown tags leave the configuration unchanged.
+func WithLocale(tag string) Option {
+	return func(c *config) {
+		tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+		l, ok := locales[tag]
+		if !ok {
+			lang, _, _ := strings.Cut(tag, "-")
+			if l, ok = locales[lang]; !ok {
+				return
+			}
+		}
+		nf := l.nf
+		c.NumberFormat = &nf
+		c.TrueWords = append(c.boolWords(true), l.truthy...)
+		c.FalseWords = append(c.boolWords(false), l.falsy...)
+	}
+}
+
+// WithBoolWords replaces the words accepted as true and false for bool fields
+// (default: yes/y/on and no/n/off). Matching is case-insensitive; the
+// strconv.ParseBool forms are always accepted.
+func WithBoolWords(truthy, falsy []string) Option {
+	return func(c *config) {
+		c.TrueWords = append([]string{}, truthy...)
+		c.FalseWords = append([]string{}, falsy...)
+	}
+}
+
+// boolWords returns the configured truthy or falsy words.
+func (c *config) boolWords(truthy bool) []string {
+	if truthy {
+		if c.TrueWords == nil {
+			return append([]string(nil), defaultTrueWords...)
+		}
+		return c.TrueWords
+	}
+	if c.FalseWords == nil {
+		return append([]string(nil), defaultFalseWords...)
+	}
+	return c.FalseWords
+}
+
+// parseBoolWord matches s against the configured boolean words.
+func parseBoolWord(s string, cfg *config) (bool, bool) {
+	s = strings.TrimSpace(s)
+	for _, w := range cfg.boolWords(true) {
+		if strings.EqualFold(s, w) {
+			return true, true
+		}
+	}
+	for _, w := range cfg.boolWords(false) {
+		if strings.EqualFold(s, w) {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// canonicalNumber rewrites s, written in format nf, as a strconv-parsable number.
+// Grouping separators must delimit groups of three digits; otherwise (and for
+// anything that is not a number) ok is false. With stripAffixes, currency
+// symbols/codes and a percent sign around the number are removed first.
+func canonicalNumber(s string, nf *NumberFormat, stripAffixes bool) (string, bool) {
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "-") {
+		neg, s = true, s[1:]
+	}
+	if stripAffixes {
+		s = trimNumberAffixes(s)
+		if !neg && strings.HasPrefix(s, "-") {
+			neg, s = true, strings.TrimSpace(s[1:])
+		}
+	}
+	var (
+		b         strings.Builder
+		digits    int // digits since the last separator
+		grouped   bool
+		seenPoint bool
+	)
+	if neg {
+		b.WriteByte('-')
+	}
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+			digits++
+		case r == nf.Decimal && !seenPoint:
+			if grouped && digits != 3 {
+				return "", false
+			}
+			b.WriteByte('.')
+			seenPoint, digits = true, 0
+		case !seenPoint && strings.ContainsRune(nf.Grouping, r):
+			if digits == 0 || digits > 3 || (grouped && digits != 3) {
+				return "", false
+			}
+			grouped, digits = true, 0
+		default:
+			return "", false
+		}
+	}
+	if grouped && !seenPoint && digits != 3 {
+		return "", false
+	}
+	out := b.String()
+	if strings.Trim(out, "-.") == "" {
+		return "", false
+	}
+	return out, true
+}
+
+// trimNumberAffixes strips currency symbols, three-letter currency codes and a
+// percent sign (plus surrounding spaces) from both ends of s.
+func trimNumberAffixes(s string) string {
+	isAffix := func(r rune) bool {
+		return r == '%' || unicode.IsSpace(r) || unicode.Is(unicode.Sc, r)
+	}
+	s = strings.TrimFunc(s, isAffix)
+	if len(s) > 3 && isCurrencyCode(s[:3]) {
+		s = s[3:]
+	}
+	if len(s) > 3 && isCurrencyCode(s[len(s)-3:]) {
+		s = s[:len(s)-3]
+	}
+	return strings.TrimFunc(s, isAffix)
+}
+
+func isCurrencyCode(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package databridge
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestLocaleNumbersAndBools(t *testing.T) {
+	type Row struct {
+		Price    float64 `json:"price"`
+		Qty      int     `json:"qty"`
+		Discount float64 `json:"discount"`
+		Active   bool    `json:"active"`
+	}
+	csv := "price,qty,discount,active\n\"1.234,56\",\"1.000\",\"12,5 %\",ja\n\"€ 3,50\",7,0,nein\n"
+	rows, err := Transform[[]Row](csv, WithLocale("de-DE"))
+	if err != nil {
+		t.Fatalf("de csv failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Price != 1234.56 || rows[0].Qty != 1000 || rows[0].Discount != 12.5 || !rows[0].Active {
+		t.Fatalf("unexpected row 0: %+v", rows)
+	}
+	if rows[1].Price != 3.5 || rows[1].Active {
+		t.Fatalf("unexpected row 1: %+v", rows[1])
+	}
+
+	fr, err := Transform[Row](url.Values{"price": {"1 234,5"}, "qty": {"1 234"}, "active": {"oui"}}, WithLocale("fr"))
+	if err != nil || fr.Price != 1234.5 || fr.Qty != 1234 || !fr.Active {
+		t.Fatalf("fr form failed: %+v err=%v", fr, err)
+	}
+}
+
+func TestNumberFormatCurrencyAndPercent(t *testing.T) {
+	type S struct {
+		Total float64 `json:"total"`
+		Rate  int     `json:"rate"`
+		Fee   float64 `json:"fee"`
+	}
+	s, err := Transform[S](`{"total":"$1,200.00","rate":"45%","fee":"-2.50 USD"}`,
+		WithNumberFormat(NumberFormat{Decimal: '.', Grouping: ","}))
+	if err != nil {
+		t.Fatalf("number format failed: %v", err)
+	}
+	if s.Total != 1200 || s.Rate != 45 || s.Fee != -2.5 {
+		t.Fatalf("unexpected values: %+v", s)
+	}
+}
+
+func TestBoolWords(t *testing.T) {
+	type S struct {
+		A bool `json:"a"`
+		B bool `json:"b"`
+		C bool `json:"c"`
+	}
+	s, err := Transform[S](`{"a":"Yes","b":"off","c":"Y"}`)
+	if err != nil || !s.A || s.B || !s.C {
+		t.Fatalf("default bool words failed: %+v err=%v", s, err)
+	}
+	s, err = Transform[S](`{"a":"enabled","b":"disabled"}`, WithBoolWords([]string{"enabled"}, []string{"disabled"}))
+	if err != nil || !s.A || s.B {
+		t.Fatalf("custom bool words failed: %+v err=%v", s, err)
+	}
+}
+
+func TestCanonicalNumberRejectsBadGrouping(t *testing.T) {
+	de := &NumberFormat{Decimal: ',', Grouping: "."}
+	for _, in := range []string{"1.5", "12.34,5", "1..000", "abc", ""} {
+		if got, ok := canonicalNumber(in, de, false); ok {
+			t.Fatalf("canonicalNumber(%q) = %q, want rejection", in, got)
+		}
+	}
+	if got, ok := canonicalNumber("1.234.567,8", de, false); !ok || got != "1234567.8" {
+		t.Fatalf("canonicalNumber grouping = %q %v", got, ok)
+	}
+}
@@ -0,0 +1,540 @@
+package databridge
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Map copies src (a struct, a map with string keys, or a pointer to either) into
+// a new Dst by walking both types with reflection, without a JSON round trip.
+// Fields are matched with the same rules as TransformToStructUniversal (JSON
+// names, field names and the KeyNormalizer), databridge tags (conv, layout) apply,
+// and compatible values are converted: numbers ↔ strings, time.Time and
+// time.Duration ↔ strings, pointers ↔ values, and slices and maps element-wise.
+// With WithStrict(true), source fields that match no destination field are an
+// error. Destination fields left unset are reported to the Logger.
+//
+// Example:
+//
+//	dto, err := databridge.Map[UserDTO](user)
+func Map[Dst any](src any, opts ...Option) (Dst, error) {
+	out, _, err := MapWithUnmapped[Dst](src, opts...)
+	return out, err
+}
+
+// MapWithUnmapped is Map that also returns the paths of destination fields that
+// no source field supplied, e.g. "address.zip".
+func MapWithUnmapped[Dst any](src any, opts ...Option) (Dst, []string, error) {
+	var out Dst
+	cfg := newConfig(opts)
+	m := &mapper{cfg: cfg}
+	if err := m.assign(reflect.ValueOf(&out).Elem(), reflect.ValueOf(src), fieldTag{}, ""); err != nil {
+		var zero Dst
+		return zero, nil, err
+	}
+	if cfg.Strict && len(m.unmatched) > 0 {
+		var zero Dst
+		return zero, nil, cfg.unknownFieldsError(reflect.TypeOf((*Dst)(nil)).Elem(), m.unmatched)
+	}
+	if len(m.unmapped) > 0 {
+		cfg.Logger("map: destination fields not supplied: %v", m.unmapped)
+	}
+	return out, m.unmapped, nil
+}
+
+// mapper carries the state of one Map call.
+type mapper struct {
+	cfg       *config
+	unmapped  []string // destination fields no source field supplied
+	unmatched []string // source fields that match no destination field
+}
+
+// assign converts src into dst. tag is the destination field's databridge tag and
+// path its dotted JSON path, used in errors.
+func (m *mapper) assign(dst, src reflect.Value, tag fieldTag, path string) (err error) {
+	if tag.Sensitive {
+		defer func() { err = redactFieldError(err) }()
+	}
+	for src.IsValid() && (src.Kind() == reflect.Ptr || src.Kind() == reflect.Interface) {
+		if src.IsNil() {
+			return nil // leave dst at its zero value
+		}
+		src = src.Elem()
+	}
+	if !src.IsValid() {
+		return nil
+	}
+	dt := dst.Type()
+	// a named converter on a slice field applies to each element
+	perElem := tag.Conv != "" && (dt.Kind() == reflect.Slice || dt.Kind() == reflect.Array) &&
+		(src.Kind() == reflect.Slice || src.Kind() == reflect.Array)
+	if !perElem && src.CanInterface() {
+		if cv, handled, err := convertWithRegistered(src.Interface(), dt, tag, m.cfg); handled {
+			if err != nil {
+				return &FieldError{Path: path, Err: err}
+			}
+			return m.set(dst, reflect.ValueOf(cv), path)
+		}
+	}
+	switch {
+	case dt.Kind() == reflect.Ptr:
+		if s, ok := stringValue(src); ok && s == "" {
+			return nil // empty strings leave pointers nil, as in Transform
+		}
+		nv := reflect.New(dt.Elem())
+		if err := m.assign(nv.Elem(), src, tag, path); err != nil {
+			return err
+		}
+		dst.Set(nv)
+		return nil
+	case dt.Kind() == reflect.Interface:
+		if src.Type().AssignableTo(dt) {
+			dst.Set(src)
+			return nil
+		}
+	case dt == timeType || dt == durationType || src.Type() == timeType || src.Type() == durationType:
+		return m.assignTime(dst, src, tag, path)
+	case dt.Kind() == reflect.Struct:
+		return m.assignStruct(dst, src, path)
+	case dt.Kind() == reflect.Map:
+		return m.assignMap(dst, src, tag, path)
+	case dt.Kind() == reflect.Slice || dt.Kind() == reflect.Array:
+		return m.assignSlice(dst, src, tag, path)
+	default:
+		if src.Type() == dt {
+			dst.Set(src)
+			return nil
+		}
+		if g, ok := genericScalar(src); ok {
+			cv, err := coerceValueForType(g, dt, tag, m.cfg, path)
+			if err != nil {
+				return err
+			}
+			return m.set(dst, reflect.ValueOf(cv), path)
+		}
+	}
+	return m.mismatch(dst, src, path)
+}
+
+// set stores v in dst, converting between compatible scalar kinds.
+func (m *mapper) set(dst, v reflect.Value, path string) error {
+	dt := dst.Type()
+	switch {
+	case !v.IsValid():
+		dst.Set(reflect.Zero(dt))
+	case v.Type().AssignableTo(dt):
+		dst.Set(v)
+	case dt.Kind() == reflect.Ptr && v.Type().AssignableTo(dt.Elem()):
+		nv := reflect.New(dt.Elem())
+		nv.Elem().Set(v)
+		dst.Set(nv)
+	case convertibleScalar(v.Type(), dt):
+		dst.Set(v.Convert(dt))
+	default:
+		return m.mismatch(dst, v, path)
+	}
+	return nil
+}
+
+func (m *mapper) mismatch(dst, src reflect.Value, path string) error {
+	return &FieldError{Path: path, Err: fmt.Errorf("cannot map %s into %s", describeValue(src), dst.Type())}
+}
+
+// describeValue names a source value for errors, including short string values.
+func describeValue(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return fmt.Sprintf("%q", v.String())
+	}
+	return v.Type().String()
+}
+
+// assignTime handles time.Time and time.Duration on either side.
+func (m *mapper) assignTime(dst, src reflect.Value, tag fieldTag, path string) error {
+	dt, st := dst.Type(), src.Type()
+	if st == dt {
+		dst.Set(src)
+		return nil
+	}
+	var g interface{}
+	switch st {
+	case timeType:
+		t := src.Interface().(time.Time)
+		switch {
+		case dt.Kind() == reflect.String:
+			layout := time.RFC3339Nano
+			if tag.Layout != "" {
+				layout = tag.Layout
+			}
+			g = t.Format(layout)
+		case isNumericKind(dt.Kind()):
+			g = t.Unix()
+		default:
+			return m.mismatch(dst, src, path)
+		}
+	case durationType:
+		d := time.Duration(src.Int())
+		switch {
+		case dt.Kind() == reflect.String:
+			g = d.String()
+		case isNumericKind(dt.Kind()):
+			g = d.Seconds()
+		default:
+			return m.mismatch(dst, src, path)
+		}
+	default:
+		var ok bool
+		if g, ok = genericScalar(src); !ok {
+			return m.mismatch(dst, src, path)
+		}
+	}
+	cv, err := coerceValueForType(g, dt, tag, m.cfg, path)
+	if err != nil {
+		return err
+	}
+	if dt == durationType {
+		if n, ok := cv.(int64); ok {
+			dst.SetInt(n)
+			return nil
+		}
+	}
+	return m.set(dst, reflect.ValueOf(cv), path)
+}
+
+// assignStruct fills the fields of dst from a struct or string-keyed map.
+func (m *mapper) assignStruct(dst, src reflect.Value, path string) error {
+	dt := dst.Type()
+	if src.Type() == dt && hasUnexportedFields(dt) {
+		dst.Set(src) // opaque types such as big.Int keep their internal state
+		return nil
+	}
+	normalize := m.normalizer()
+	fields := mapFields(dt)
+	// JSON names claim keys before Go field names, earlier fields before later ones
+	lookup := make(map[string]int, 2*len(fields))
+	claim := func(key string, i int) {
+		if _, taken := lookup[key]; !taken {
+			lookup[key] = i
+		}
+	}
+	for i, f := range fields {
+		if normalize == nil {
+			claim(f.name, i)
+		} else {
+			claim(normalize(f.name), i)
+		}
+	}
+	if normalize != nil {
+		for i, f := range fields {
+			claim(normalize(f.Name), i)
+		}
+	}
+	supplied := make(map[int]bool, len(fields))
+	// visit assigns v to the destination field matching the first of names
+	visit := func(v reflect.Value, names ...string) error {
+		for _, name := range names {
+			key := name
+			if normalize != nil {
+				key = normalize(key)
+			}
+			i, ok := lookup[key]
+			if !ok {
+				continue
+			}
+			if supplied[i] {
+				return nil // first source field wins
+			}
+			supplied[i] = true
+			f := fields[i]
+			tag := f.tag
+			tag.Sensitive = m.cfg.sensitive(f.name, tag)
+			fv, err := fieldByIndexAlloc(dst, f.Index)
+			if err != nil {
+				return &FieldError{Path: joinFieldPath(path, f.name), Err: err}
+			}
+			return m.assign(fv, v, tag, joinFieldPath(path, f.name))
+		}
+		m.unmatched = append(m.unmatched, joinFieldPath(path, names[0]))
+		return nil
+	}
+	switch src.Kind() {
+	case reflect.Struct:
+		for _, f := range mapFields(src.Type()) {
+			fv, err := src.FieldByIndexErr(f.Index)
+			if err != nil {
+				continue // nil embedded pointer
+			}
+			if err := visit(fv, f.name, f.Name); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if src.Type().Key().Kind() != reflect.String {
+			return m.mismatch(dst, src, path)
+		}
+		keys := src.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			if err := visit(src.MapIndex(k), k.String()); err != nil {
+				return err
+			}
+		}
+	default:
+		return m.mismatch(dst, src, path)
+	}
+	for i, f := range fields {
+		if !supplied[i] {
+			m.unmapped = append(m.unmapped, joinFieldPath(path, f.name))
+		}
+	}
+	return nil
+}
+
+// mapField is a struct field as encoding/json sees it; Index is its path
+// through embedded structs.
+type mapField struct {
+	reflect.StructField
+	name string // JSON name
+	tag  fieldTag
+}
+
+var mapFieldsCache sync.Map // reflect.Type -> []mapField
+
+// mapFields returns the fields of t with the fields of embedded structs that
+// have no JSON name promoted, as encoding/json does: a shallower field hides
+// deeper ones of the same name, and at equal depth a JSON-tagged field wins
+// while two untagged ones hide each other.
+func mapFields(t reflect.Type) []mapField {
+	if cached, ok := mapFieldsCache.Load(t); ok {
+		return cached.([]mapField)
+	}
+	type candidate struct {
+		f      mapField
+		depth  int
+		tagged bool
+	}
+	var cands []candidate
+	seen := map[reflect.Type]bool{}
+	var walk func(t reflect.Type, index []int, depth int)
+	walk = func(t reflect.Type, index []int, depth int) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			idx := append(append([]int(nil), index...), i)
+			jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if f.Anonymous && jsonName == "" {
+				ft := f.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					walk(ft, idx, depth+1)
+					continue
+				}
+			}
+			name, ok := jsonFieldName(f)
+			if !ok {
+				continue
+			}
+			f.Index = idx
+			mf := mapField{StructField: f, name: name, tag: parseFieldTag(f.Tag.Get("databridge"))}
+			cands = append(cands, candidate{mf, depth, jsonName != ""})
+		}
+	}
+	walk(t, nil, 0)
+
+	// keep the dominant field of each name
+	byName := map[string][]int{}
+	for i, c := range cands {
+		byName[c.f.name] = append(byName[c.f.name], i)
+	}
+	keep := make(map[int]bool, len(cands))
+	for _, idx := range byName {
+		min := cands[idx[0]].depth
+		for _, i := range idx {
+			if cands[i].depth < min {
+				min = cands[i].depth
+			}
+		}
+		var top, tagged []int
+		for _, i := range idx {
+			if cands[i].depth == min {
+				top = append(top, i)
+				if cands[i].tagged {
+					tagged = append(tagged, i)
+				}
+			}
+		}
+		switch {
+		case len(top) == 1:
+			keep[top[0]] = true
+		case len(tagged) == 1:
+			keep[tagged[0]] = true
+		}
+	}
+	fields := make([]mapField, 0, len(keep))
+	for i, c := range cands {
+		if keep[i] {
+			fields = append(fields, c.f)
+		}
+	}
+	mapFieldsCache.Store(t, fields)
+	return fields
+}
+
+// fieldByIndexAlloc is Value.FieldByIndex for setting: nil embedded pointers on
+// the way are allocated. Like encoding/json it fails on a nil embedded pointer
+// to an unexported struct, which cannot be set.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// assignMap fills a map from a map (converting keys and values) or from a struct
+// (keyed by JSON field names).
+func (m *mapper) assignMap(dst, src reflect.Value, tag fieldTag, path string) error {
+	dt := dst.Type()
+	out := reflect.MakeMap(dt)
+	put := func(key, v reflect.Value, sub string) error {
+		k := reflect.New(dt.Key()).Elem()
+		if err := m.assign(k, key, fieldTag{}, sub); err != nil {
+			return err
+		}
+		e := reflect.New(dt.Elem()).Elem()
+		if err := m.assign(e, v, tag, sub); err != nil {
+			return err
+		}
+		out.SetMapIndex(k, e)
+		return nil
+	}
+	switch src.Kind() {
+	case reflect.Map:
+		for _, k := range src.MapKeys() {
+			if err := put(k, src.MapIndex(k), joinFieldPath(path, fmt.Sprint(k.Interface()))); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for _, f := range mapFields(src.Type()) {
+			fv, err := src.FieldByIndexErr(f.Index)
+			if err != nil {
+				continue // nil embedded pointer
+			}
+			if err := put(reflect.ValueOf(f.name), fv, joinFieldPath(path, f.name)); err != nil {
+				return err
+			}
+		}
+	default:
+		return m.mismatch(dst, src, path)
+	}
+	dst.Set(out)
+	return nil
+}
+
+// assignSlice fills a slice or array element-wise; string and []byte convert directly.
+func (m *mapper) assignSlice(dst, src reflect.Value, tag fieldTag, path string) error {
+	dt := dst.Type()
+	if src.Kind() == reflect.String && dt.Kind() == reflect.Slice && dt.Elem().Kind() == reflect.Uint8 {
+		dst.SetBytes([]byte(src.String()))
+		return nil
+	}
+	if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+		return m.mismatch(dst, src, path)
+	}
+	n := src.Len()
+	if dt.Kind() == reflect.Array {
+		if n > dt.Len() {
+			return &FieldError{Path: path, Err: fmt.Errorf("%d elements do not fit in %s", n, dt)}
+		}
+	} else {
+		if src.Kind() == reflect.Slice && src.IsNil() {
+			return nil
+		}
+		dst.Set(reflect.MakeSlice(dt, n, n))
+	}
+	for i := 0; i < n; i++ {
+		if err := m.assign(dst.Index(i), src.Index(i), tag, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizer returns the key normalizer in effect, or nil when keys must match exactly.
+func (m *mapper) normalizer() func(string) string {
+	if !m.cfg.NormalizeKeys {
+		return nil
+	}
+	return m.cfg.KeyNormalizer
+}
+
+// genericScalar returns a scalar in the generic shape used by the decode pipeline
+// (string, bool, int64, uint64 or float64).
+func genericScalar(v reflect.Value) (interface{}, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return nil, false
+}
+
+func stringValue(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.String {
+		return v.String(), true
+	}
+	return "", false
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// convertibleScalar reports whether a value of type from may be converted to to
+// without changing its meaning; integer-to-string (rune) conversions are excluded.
+func convertibleScalar(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	fk, tk := from.Kind(), to.Kind()
+	switch {
+	case fk == tk:
+		return true
+	case isNumericKind(fk) && isNumericKind(tk):
+		return true
+	}
+	return false
+}
+
+func hasUnexportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			return true
+		}
+	}
+	return false
+}
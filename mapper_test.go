@@ -0,0 +1,178 @@
+package databridge
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mapDomainAddress struct {
+	City string
+	Zip  int
+}
+
+type mapDomainUser struct {
+	ID        int64
+	UserName  string
+	Email     *string
+	Joined    time.Time
+	Timeout   time.Duration
+	Scores    []int
+	Address   *mapDomainAddress
+	Labels    map[string]int
+	Internal  string `json:"-"`
+	createdBy string
+}
+
+type mapUserDTO struct {
+	ID       string            `json:"id"`
+	UserName string            `json:"user_name"`
+	Email    string            `json:"email"`
+	Joined   string            `json:"joined" databridge:"layout=2006-01-02"`
+	Timeout  string            `json:"timeout"`
+	Scores   []string          `json:"scores"`
+	Address  mapAddressDTO     `json:"address"`
+	Labels   map[string]string `json:"labels"`
+	Nickname string            `json:"nickname"`
+}
+
+type mapAddressDTO struct {
+	City    string `json:"city"`
+	ZipCode string `json:"zip"`
+}
+
+func TestMapStructToStruct(t *testing.T) {
+	email := "ada@example.com"
+	src := mapDomainUser{
+		ID:        42,
+		UserName:  "ada",
+		Email:     &email,
+		Joined:    time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC),
+		Timeout:   90 * time.Second,
+		Scores:    []int{1, 2},
+		Address:   &mapDomainAddress{City: "London", Zip: 12345},
+		Labels:    map[string]int{"a": 1},
+		Internal:  "secret",
+		createdBy: "x",
+	}
+	dto, unmapped, err := MapWithUnmapped[mapUserDTO](&src)
+	if err != nil {
+		t.Fatalf("map failed: %v", err)
+	}
+	want := mapUserDTO{
+		ID: "42", UserName: "ada", Email: "ada@example.com", Joined: "2024-03-05", Timeout: "1m30s",
+		Scores: []string{"1", "2"}, Address: mapAddressDTO{City: "London", ZipCode: "12345"},
+		Labels: map[string]string{"a": "1"},
+	}
+	if !reflect.DeepEqual(dto, want) {
+		t.Fatalf("map = %+v, want %+v", dto, want)
+	}
+	if !reflect.DeepEqual(unmapped, []string{"nickname"}) {
+		t.Fatalf("unmapped = %v, want [nickname]", unmapped)
+	}
+
+	// and back again
+	back, err := Map[mapDomainUser](dto)
+	if err != nil {
+		t.Fatalf("map back failed: %v", err)
+	}
+	src.Internal, src.createdBy = "", ""
+	src.Joined = time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	if !reflect.DeepEqual(back, src) {
+		t.Fatalf("map back = %+v, want %+v", back, src)
+	}
+	back.Scores[0] = 99
+	if dto.Scores[0] != "1" {
+		t.Fatalf("mapped slice aliases the source")
+	}
+}
+
+func TestMapFromMapAndStrict(t *testing.T) {
+	got, err := Map[mapDomainAddress](map[string]interface{}{"CITY": "Paris", "zip": "75001", "extra": true})
+	if err != nil || got != (mapDomainAddress{City: "Paris", Zip: 75001}) {
+		t.Fatalf("map from map = %+v err=%v", got, err)
+	}
+	if _, err := Map[mapDomainAddress](map[string]interface{}{"extra": true}, WithStrict(true)); err == nil || !strings.Contains(err.Error(), "extra") {
+		t.Fatalf("expected strict error naming extra, got %v", err)
+	}
+}
+
+func TestMapConversionErrors(t *testing.T) {
+	_, err := Map[mapDomainAddress](mapAddressDTO{City: "Paris", ZipCode: "abc"})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "Zip" {
+		t.Fatalf("expected FieldError at Zip, got %v", err)
+	}
+	_, err = Map[struct{ N int8 }](struct{ N int }{N: 300})
+	if !errors.As(err, &fe) || fe.Path != "N" {
+		t.Fatalf("expected overflow FieldError at N, got %v", err)
+	}
+}
+
+type mapBase struct {
+	ID      int64     `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type mapAudit struct {
+	By string `json:"by"`
+}
+
+type mapEntity struct {
+	mapBase
+	*mapAudit
+	Name string
+}
+
+type mapEntityDTO struct {
+	ID      string `json:"id"`
+	Created string `json:"created"`
+	mapAuditDTO
+	Name string `json:"name"`
+}
+
+type mapAuditDTO struct {
+	By string `json:"by"`
+}
+
+func TestMapEmbeddedStructs(t *testing.T) {
+	src := mapEntity{mapBase: mapBase{ID: 7, Created: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, mapAudit: &mapAudit{By: "ops"}, Name: "x"}
+	dto, unmapped, err := MapWithUnmapped[mapEntityDTO](src, WithStrict(true))
+	if err != nil {
+		t.Fatalf("map failed: %v", err)
+	}
+	if dto.ID != "7" || dto.Created != "2024-01-02T00:00:00Z" || dto.Name != "x" || dto.By != "ops" {
+		t.Fatalf("embedded fields not promoted: %+v", dto)
+	}
+	if len(unmapped) != 0 {
+		t.Fatalf("unmapped = %v", unmapped)
+	}
+
+	// promoted destination fields; the embedded pointer is left nil when unused
+	back, unmapped, err := MapWithUnmapped[mapEntity](map[string]interface{}{"id": "9", "created": "2024-01-02T00:00:00Z", "name": "y"})
+	if err != nil || back.ID != 9 || !back.Created.Equal(src.Created) || back.Name != "y" || back.mapAudit != nil {
+		t.Fatalf("map back = %+v err=%v", back, err)
+	}
+	if !reflect.DeepEqual(unmapped, []string{"by"}) {
+		t.Fatalf("unmapped = %v", unmapped)
+	}
+
+	// a nil embedded pointer on the source supplies nothing
+	dto, unmapped, err = MapWithUnmapped[mapEntityDTO](mapEntity{Name: "z"})
+	if err != nil || dto.Name != "z" || !reflect.DeepEqual(unmapped, []string{"by"}) {
+		t.Fatalf("nil embedded source = %+v unmapped=%v err=%v", dto, unmapped, err)
+	}
+}
+
+func TestMapEmbeddedUnexportedPointer(t *testing.T) {
+	type dst struct {
+		*mapAudit
+	}
+	_, err := Map[dst](map[string]interface{}{"by": "ops"})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "by" {
+		t.Fatalf("expected FieldError at by, got %v", err)
+	}
+}
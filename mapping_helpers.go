@@ -2,8 +2,10 @@ package databridge
 
 import (
 	"fmt"
+	"log/slog"
 	"net/url"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -30,7 +32,7 @@ func formValuesToMapWithDots(vals url.Values, cfg *config) map[string]interface{
 		}
 		if len(arr) == 1 {
 			if cfg.AllowNumberConv {
-				out[k] = stringToBestType(arr[0])
+				out[k] = stringToBestType(arr[0], cfg)
 			} else {
 				out[k] = arr[0]
 			}
@@ -38,7 +40,7 @@ func formValuesToMapWithDots(vals url.Values, cfg *config) map[string]interface{
 			tmp := make([]interface{}, 0, len(arr))
 			for _, s := range arr {
 				if cfg.AllowNumberConv {
-					tmp = append(tmp, stringToBestType(s))
+					tmp = append(tmp, stringToBestType(s, cfg))
 				} else {
 					tmp = append(tmp, s)
 				}
@@ -58,7 +60,7 @@ func assignNestedValue(m map[string]interface{}, parts []string, arr []string, c
 		// assign value
 		if len(arr) == 1 {
 			if cfg.AllowNumberConv {
-				m[head] = stringToBestType(arr[0])
+				m[head] = stringToBestType(arr[0], cfg)
 			} else {
 				m[head] = arr[0]
 			}
@@ -66,7 +68,7 @@ func assignNestedValue(m map[string]interface{}, parts []string, arr []string, c
 			tmp := make([]interface{}, 0, len(arr))
 			for _, s := range arr {
 				if cfg.AllowNumberConv {
-					tmp = append(tmp, stringToBestType(s))
+					tmp = append(tmp, stringToBestType(s, cfg))
 				} else {
 					tmp = append(tmp, s)
 				}
@@ -98,23 +100,116 @@ func normalizeDotsToNested(row map[string]interface{}) map[string]interface{} {
 	out := make(map[string]interface{})
 	for k, v := range row {
 		if strings.Contains(k, ".") {
-			parts := strings.Split(k, ".")
-			assignNestedValue(out, parts, []string{fmt.Sprintf("%v", v)}, &config{AllowNumberConv: true})
-			continue
+			setNestedValue(out, strings.Split(k, "."), v)
+		}
+	}
+	for k, v := range row {
+		if _, exists := out[k]; !exists && !strings.Contains(k, ".") {
+			out[k] = v
 		}
-		out[k] = v
 	}
 	return out
 }
 
+// setNestedValue stores v at parts in m, creating (or replacing other values
+// with) nested maps on the way.
+func setNestedValue(m map[string]interface{}, parts []string, v interface{}) {
+	for _, p := range parts[:len(parts)-1] {
+		nm, ok := m[p].(map[string]interface{})
+		if !ok {
+			nm = make(map[string]interface{})
+			m[p] = nm
+		}
+		m = nm
+	}
+	m[parts[len(parts)-1]] = v
+}
+
 // mapToStructKeysRecursive and related helpers
 
 type fieldInfo struct {
 	JSONName  string
 	FieldType reflect.Type
+	Index     int // index of the field in its struct
+	Tag       fieldTag
+}
+
+// fieldTag holds the options parsed from a `databridge:"..."` struct tag.
+// Options are comma-separated; values containing commas can be single-quoted,
+// e.g. `databridge:"conv=money"` or `databridge:"layout='Jan 2, 2006'"`.
+type fieldTag struct {
+	Conv   string // named converter registered via RegisterNamedConverter / WithNamedConverter
+	Layout string // time layout for time.Time fields, e.g. layout='Jan 2, 2006'
+	// slice strategy in merge mode: "replace", "append" or "key"; MergeKey names
+	// the element field matched by "key" (default "id")
+	Merge    string
+	MergeKey string
+	// Path binds the field from a deeper location of the input object, e.g.
+	// path=data.attributes.name, path=/data/items/0 or path='$.items[*].id'
+	Path     string
+	pathSegs []pathSegment
+	// Str overrides the configured StringPolicy, e.g. str='trim,max=40' or str=raw
+	Str *StringPolicy
+	// Sensitive masks the field's value in errors, logs and reports
+	Sensitive bool
 }
 
-func mapToStructKeysRecursive(in map[string]interface{}, typ reflect.Type, cfg *config) (map[string]interface{}, []string) {
+// parseFieldTag parses the value of a `databridge` struct tag.
+func parseFieldTag(tag string) fieldTag {
+	var ft fieldTag
+	for _, opt := range splitTagOptions(tag) {
+		key, val, _ := strings.Cut(opt, "=")
+		switch strings.TrimSpace(key) {
+		case "conv":
+			ft.Conv = val
+		case "layout":
+			ft.Layout = val
+		case "merge":
+			ft.Merge = val
+		case "mergekey":
+			ft.MergeKey = val
+		case "path":
+			ft.Path = val
+			ft.pathSegs = parsePath(val)
+		case "str":
+			ft.Str = parseStringPolicy(val)
+		case "sensitive":
+			ft.Sensitive = true
+		}
+	}
+	return ft
+}
+
+// splitTagOptions splits a tag value on commas that are not inside single quotes,
+// stripping the quotes from quoted values.
+func splitTagOptions(tag string) []string {
+	var (
+		out    []string
+		cur    strings.Builder
+		quoted bool
+	)
+	for _, r := range tag {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			out = append(out, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+// mapToStructKeysRecursive renames the (normalized) keys of in to the JSON names of
+// typ's fields, recursing into nested structs. It returns the mapped map, the
+// paths of keys that matched no field, and the paths of fields that were supplied
+// (including explicit nulls).
+func mapToStructKeysRecursive(in map[string]interface{}, typ reflect.Type, cfg *config) (map[string]interface{}, []string, []string) {
 	if in == nil {
 		in = map[string]interface{}{}
 	}
@@ -123,100 +218,344 @@ func mapToStructKeysRecursive(in map[string]interface{}, typ reflect.Type, cfg *
 	}
 	out := make(map[string]interface{})
 	unmatched := []string{}
+	matched := []string{}
 
 	// build normalized lookup of struct fields
-	fieldLookup := buildFieldLookup(typ, cfg.KeyNormalizer)
-
-	seen := map[string]bool{}
-	for normKey, info := range fieldLookup {
-		if v, ok := in[normKey]; ok {
-			// nested struct handling
-			if info.FieldType.Kind() == reflect.Struct || (info.FieldType.Kind() == reflect.Ptr && info.FieldType.Elem().Kind() == reflect.Struct) {
-				if subMap, ok := v.(map[string]interface{}); ok {
-					mappedSub, subUnmatched := mapToStructKeysRecursive(subMap, info.FieldType, cfg)
-					out[info.JSONName] = mappedSub
-					for _, um := range subUnmatched {
-						unmatched = append(unmatched, info.JSONName+"."+um)
-					}
-				} else {
-					out[info.JSONName] = v
+	entry := cfg.fieldLookupEntry(typ)
+	for _, c := range entry.collisions {
+		cfg.recordCollision(c)
+	}
+	normalize := cfg.inputNormalizer()
+	norm := func(k string) string {
+		if normalize == nil {
+			return k
+		}
+		return normalize(k)
+	}
+
+	bind := func(info fieldInfo, v interface{}) {
+		leave := cfg.explain.enter(info.JSONName)
+		mv, subUnmatched, subMatched := mapNestedValue(v, info.FieldType, cfg)
+		leave()
+		out[info.JSONName] = mv
+		for _, um := range subUnmatched {
+			unmatched = append(unmatched, joinSubPath(info.JSONName, um))
+		}
+		for _, sm := range subMatched {
+			matched = append(matched, joinSubPath(info.JSONName, sm))
+		}
+		matched = append(matched, info.JSONName)
+	}
+
+	// pick one input key per field: an exact JSON name beats a normalized JSON
+	// name, which beats a normalized Go field name; ties go to the smallest key
+	type pick struct {
+		info fieldInfo
+		key  string
+		rank int
+		keys []string
+	}
+	picks := map[int]*pick{}
+	leftovers := map[string][]string{} // normalized key -> input keys
+	for k := range in {
+		nk := norm(k)
+		info, ok := entry.fields[nk]
+		if !ok || info.Tag.Path != "" {
+			// path fields are bound only from their path, below
+			leftovers[nk] = append(leftovers[nk], k)
+			continue
+		}
+		rank := 2
+		if k == info.JSONName {
+			rank = 0
+		} else if nk == norm(info.JSONName) {
+			rank = 1
+		}
+		p := picks[info.Index]
+		if p == nil {
+			picks[info.Index] = &pick{info: info, key: k, rank: rank, keys: []string{k}}
+			continue
+		}
+		p.keys = append(p.keys, k)
+		if rank < p.rank || (rank == p.rank && k < p.key) {
+			p.key, p.rank = k, rank
+		}
+	}
+	// bind and report in normalized key order so logs and errors are stable
+	ordered := make([]*pick, 0, len(picks))
+	for _, p := range picks {
+		ordered = append(ordered, p)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return norm(ordered[i].info.JSONName) < norm(ordered[j].info.JSONName) })
+	bound := make(map[int]bool, len(picks))
+	for _, p := range ordered {
+		bound[p.info.Index] = true
+		bind(p.info, in[p.key])
+		cfg.explain.key(p.key, norm(p.key), p.info.JSONName, [...]string{"json name", "normalized", "field name"}[p.rank])
+		if len(p.keys) > 1 {
+			cfg.recordCollision(KeyCollision{Type: typ.String(), Field: p.info.JSONName, Sources: p.keys, Winner: p.key})
+			for _, k := range p.keys {
+				if k != p.key {
+					cfg.explain.key(k, norm(k), "", "collision")
 				}
-			} else {
-				out[info.JSONName] = v
 			}
-			seen[normKey] = true
 		}
 	}
 
-	// keep leftover keys (unmatched)
-	for k, v := range in {
-		if _, s := seen[k]; s {
-			continue
+	for _, info := range entry.pathFields {
+		segs := info.Tag.pathSegs
+		if v, ok := resolvePath(in, segs, normalize); ok {
+			bound[info.Index] = true
+			bind(info, v)
+			cfg.explain.key(info.Tag.Path, "", info.JSONName, "path")
+			// the envelope key the path starts from is consumed, not unknown
+			if len(segs) > 0 && !segs[0].Wildcard {
+				delete(leftovers, norm(segs[0].Key))
+			}
+		}
+	}
+
+	// bind near-miss keys to fields nothing else supplied; strict mode reports
+	// them with suggestions instead
+	if cfg.FuzzyThreshold > 0 && !cfg.Strict && len(leftovers) > 0 {
+		nks := make([]string, 0, len(leftovers))
+		for nk := range leftovers {
+			nks = append(nks, nk)
+		}
+		sort.Strings(nks)
+		for _, nk := range nks {
+			info, ok := bestFieldMatch(nk, entry, bound, cfg.FuzzyThreshold)
+			if !ok {
+				continue
+			}
+			keys := leftovers[nk]
+			sort.Strings(keys)
+			bound[info.Index] = true
+			bind(info, in[keys[0]])
+			delete(leftovers, nk)
+			cfg.Logger("fuzzy key match: %q -> field %q", keys[0], info.JSONName)
+			cfg.logEvent(slog.LevelInfo, "fuzzy key match", slog.String("key", keys[0]), slog.String("field", info.JSONName))
+			cfg.explain.key(keys[0], nk, info.JSONName, "fuzzy")
 		}
-		out[k] = v
-		unmatched = append(unmatched, k)
 	}
 
-	return out, unmatched
+	// keep leftover keys (unmatched) under their normalized names
+	nks := make([]string, 0, len(leftovers))
+	for nk := range leftovers {
+		nks = append(nks, nk)
+	}
+	sort.Strings(nks)
+	for _, nk := range nks {
+		keys := leftovers[nk]
+		winner := keys[0]
+		for _, k := range keys[1:] {
+			if winner != nk && (k == nk || k < winner) {
+				winner = k
+			}
+		}
+		if len(keys) > 1 {
+			cfg.recordCollision(KeyCollision{Type: typ.String(), Sources: keys, Winner: winner})
+		}
+		out[nk] = normalizeValueKeys(in[winner], normalize)
+		unmatched = append(unmatched, nk)
+		for _, k := range keys {
+			cfg.explain.key(k, nk, "", "unknown")
+		}
+	}
+
+	return out, unmatched, matched
+}
+
+// mapNestedValue maps the keys of a value headed for a field of type ft: objects
+// for nested structs and arrays of objects for slices of structs. The keys of
+// other objects (e.g. for map fields) are only normalized. Sub-paths are relative to the field ("city", "[0].name").
+func mapNestedValue(v interface{}, ft reflect.Type, cfg *config) (interface{}, []string, []string) {
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	switch ft.Kind() {
+	case reflect.Struct:
+		if subMap, ok := v.(map[string]interface{}); ok {
+			return mapToStructKeysRecursive(subMap, ft, cfg)
+		}
+	case reflect.Slice, reflect.Array:
+		arr, ok := v.([]interface{})
+		et := ft.Elem()
+		if et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+		if !ok || et.Kind() != reflect.Struct {
+			break
+		}
+		var unmatched, matched []string
+		out := make([]interface{}, len(arr))
+		for i, e := range arr {
+			em, ok := e.(map[string]interface{})
+			if !ok {
+				out[i] = e
+				continue
+			}
+			idx := fmt.Sprintf("[%d]", i)
+			leave := cfg.explain.enter(idx)
+			mapped, subUnmatched, subMatched := mapToStructKeysRecursive(em, et, cfg)
+			leave()
+			out[i] = mapped
+			for _, um := range subUnmatched {
+				unmatched = append(unmatched, joinSubPath(idx, um))
+			}
+			for _, sm := range subMatched {
+				matched = append(matched, joinSubPath(idx, sm))
+			}
+		}
+		return out, unmatched, matched
+	}
+	return normalizeValueKeys(v, cfg.inputNormalizer()), nil, nil
+}
+
+// joinSubPath appends a relative path ("city" or "[0].city") to a field path.
+func joinSubPath(path, sub string) string {
+	if path == "" {
+		return sub
+	}
+	if strings.HasPrefix(sub, "[") {
+		return path + sub
+	}
+	return path + "." + sub
 }
 
 var (
 	fieldLookupCache sync.Map // key: fieldCacheKey -> map[string]fieldInfo
 )
 
+// fieldCacheKey identifies a cached lookup: the struct type and the key matching
+// strategy whose normalizer produced its keys ("json" for raw JSON names).
 type fieldCacheKey struct {
 	t       reflect.Type
-	hasNorm bool
+	matcher string
 }
 
-func buildFieldLookup(typ reflect.Type, normalizer func(string) string) map[string]fieldInfo {
+// jsonFieldName returns the JSON name of a struct field; ok is false for
+// unexported fields and fields tagged `json:"-"`.
+func jsonFieldName(f reflect.StructField) (name string, ok bool) {
+	if f.PkgPath != "" { // unexported
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ = strings.Cut(tag, ","); name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+// fieldLookupEntry is the cached field lookup of a struct type.
+type fieldLookupEntry struct {
+	fields     map[string]fieldInfo // normalized key -> field
+	pathFields []fieldInfo          // fields bound by a path tag
+	collisions []KeyCollision       // struct fields competing for one normalized key
+}
+
+// jsonFieldLookup returns typ's fields keyed by their raw JSON names.
+func jsonFieldLookup(typ reflect.Type) map[string]fieldInfo {
+	return buildFieldLookup(typ, nil, "json").fields
+}
+
+// fieldLookup returns typ's fields keyed by the configured normalizer.
+func (c *config) fieldLookup(typ reflect.Type) map[string]fieldInfo {
+	return c.fieldLookupEntry(typ).fields
+}
+
+// fieldLookupEntry returns the lookup for the configured normalizer. Lookups for
+// built-in strategies are cached process-wide; those for a custom normalizer only
+// for the duration of the call, since the function's identity is unknown.
+func (c *config) fieldLookupEntry(typ reflect.Type) *fieldLookupEntry {
+	if c.KeyNormalizer == nil {
+		return buildFieldLookup(typ, nil, "json")
+	}
+	if c.keyMatcherID != "" {
+		return buildFieldLookup(typ, c.KeyNormalizer, c.keyMatcherID)
+	}
+	if l, ok := c.lookups[typ]; ok {
+		return l
+	}
+	l := buildFieldLookup(typ, c.KeyNormalizer, "")
+	if c.lookups == nil {
+		c.lookups = map[reflect.Type]*fieldLookupEntry{}
+	}
+	c.lookups[typ] = l
+	return l
+}
+
+// inputNormalizer returns the normalizer applied to input keys, or nil when key
+// normalization is off.
+func (c *config) inputNormalizer() func(string) string {
+	if !c.NormalizeKeys {
+		return nil
+	}
+	return c.KeyNormalizer
+}
+
+// buildFieldLookup maps the normalized JSON names and field names of typ's fields
+// to their fieldInfo. When several fields claim a key, a JSON name beats a Go
+// field name and otherwise the first declared field wins. cacheID names the
+// normalizer for the process-wide cache; an empty cacheID disables caching.
+func buildFieldLookup(typ reflect.Type, normalizer func(string) string, cacheID string) *fieldLookupEntry {
 	// Use the underlying (non-pointer) type for caching identity
-	out := map[string]fieldInfo{}
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
 	}
 	if typ.Kind() != reflect.Struct {
-		return out
+		return &fieldLookupEntry{fields: map[string]fieldInfo{}}
+	}
+	key := fieldCacheKey{t: typ, matcher: cacheID}
+	if cacheID != "" {
+		if cached, ok := fieldLookupCache.Load(key); ok {
+			return cached.(*fieldLookupEntry)
+		}
 	}
-	key := fieldCacheKey{t: typ, hasNorm: normalizer != nil}
-	if cached, ok := fieldLookupCache.Load(key); ok {
-		return cached.(map[string]fieldInfo)
+	entry := &fieldLookupEntry{fields: map[string]fieldInfo{}}
+	rank := map[string]int{}        // 0 = JSON name, 1 = Go field name
+	claims := map[string][]string{} // normalized key -> Go names of the claiming fields
+	claim := func(norm string, fi fieldInfo, r int, goName string) {
+		cur, ok := entry.fields[norm]
+		if ok && cur.Index == fi.Index {
+			return
+		}
+		claims[norm] = append(claims[norm], goName)
+		if ok && rank[norm] <= r {
+			return
+		}
+		entry.fields[norm], rank[norm] = fi, r
 	}
 	for i := 0; i < typ.NumField(); i++ {
 		f := typ.Field(i)
-		if f.PkgPath != "" { // unexported
+		jsonName, ok := jsonFieldName(f)
+		if !ok {
 			continue
 		}
-		tag := f.Tag.Get("json")
-		jsonName := ""
-		if tag == "-" {
-			continue
-		}
-		if tag != "" {
-			parts := strings.Split(tag, ",")
-			if parts[0] != "" {
-				jsonName = parts[0]
-			}
+		fi := fieldInfo{JSONName: jsonName, FieldType: f.Type, Index: i, Tag: parseFieldTag(f.Tag.Get("databridge"))}
+		if fi.Tag.Path != "" {
+			entry.pathFields = append(entry.pathFields, fi)
 		}
-		if jsonName == "" {
-			jsonName = f.Name
-		}
-		norm := jsonName
-		if normalizer != nil {
-			norm = normalizer(norm)
+		if normalizer == nil {
+			claim(jsonName, fi, 0, f.Name)
+			continue
 		}
-		out[norm] = fieldInfo{JSONName: jsonName, FieldType: f.Type}
+		claim(normalizer(jsonName), fi, 0, f.Name)
 		// alias by normalized field name
-		if normalizer != nil {
-			norm2 := normalizer(f.Name)
-			out[norm2] = out[norm]
+		claim(normalizer(f.Name), fi, 1, f.Name)
+	}
+	for norm, names := range claims {
+		if len(names) > 1 {
+			winner := typ.Field(entry.fields[norm].Index).Name
+			entry.collisions = append(entry.collisions, KeyCollision{Type: typ.String(), Field: entry.fields[norm].JSONName, Sources: names, Winner: winner})
 		}
 	}
-	// store a copy to avoid accidental mutation
-	copied := make(map[string]fieldInfo, len(out))
-	for k, v := range out {
-		copied[k] = v
+	sort.Slice(entry.collisions, func(i, j int) bool { return entry.collisions[i].Field < entry.collisions[j].Field })
+	if cacheID != "" {
+		fieldLookupCache.Store(key, entry)
 	}
-	fieldLookupCache.Store(key, copied)
-	return copied
+	return entry
 }
@@ -0,0 +1,210 @@
+package databridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// SliceStrategy controls how slices are combined in merge mode.
+type SliceStrategy int
+
+const (
+	// SliceReplace replaces the existing slice with the supplied one (default).
+	SliceReplace SliceStrategy = iota
+	// SliceAppend appends the supplied elements to the existing ones.
+	SliceAppend
+	// SliceMergeByKey merges supplied elements into existing elements with the
+	// same key field (see WithMergeKey) and appends the rest.
+	SliceMergeByKey
+)
+
+// WithMerge overlays the input onto the value already held by the output instead
+// of replacing it: only supplied fields are written, nested structs and maps are
+// merged deeply, and slices follow the configured SliceStrategy. On error the
+// output may be partially updated.
+func WithMerge(enabled bool) Option {
+	return func(c *config) { c.Merge = enabled }
+}
+
+// WithSliceStrategy sets how slices are combined in merge mode. A field can
+// override it with `databridge:"merge=replace|append|key"`.
+func WithSliceStrategy(s SliceStrategy) Option {
+	return func(c *config) { c.SliceStrategy = s }
+}
+
+// WithMergeKey sets the element field (JSON name) used by SliceMergeByKey; the
+// default is "id". A field can override it with `databridge:"mergekey=sku"`.
+func WithMergeKey(key string) Option {
+	return func(c *config) { c.MergeKey = key }
+}
+
+// TransformInto overlays input onto existing, a non-nil pointer to a struct or
+// slice already holding values (e.g. defaults loaded from a file). It is
+// TransformToStructUniversal with WithMerge(true).
+//
+// Example:
+//
+//	cfg := loadDefaults()
+//	_ = databridge.TransformInto(&cfg, overridesYAML, databridge.WithYAML(true), databridge.WithSliceStrategy(databridge.SliceAppend))
+func TransformInto(existing interface{}, input interface{}, opts ...Option) error {
+	return TransformToStructUniversal(input, existing, append(opts, WithMerge(true))...)
+}
+
+// mergeKey returns the configured SliceMergeByKey key, "id" by default.
+func (c *config) mergeKey() string {
+	if c.MergeKey == "" {
+		return "id"
+	}
+	return c.MergeKey
+}
+
+// sliceStrategyFor returns the strategy and key for a field, honouring its tag.
+func sliceStrategyFor(tag fieldTag, cfg *config) (SliceStrategy, string) {
+	strategy, key := cfg.SliceStrategy, cfg.mergeKey()
+	switch tag.Merge {
+	case "replace":
+		strategy = SliceReplace
+	case "append":
+		strategy = SliceAppend
+	case "key":
+		strategy = SliceMergeByKey
+	}
+	if tag.MergeKey != "" {
+		key = tag.MergeKey
+	}
+	return strategy, key
+}
+
+// prepareMerge rewrites mapped (keys are JSON names, values already coerced) so that
+// decoding it onto cur yields the merged result: maps and appended/keyed slices are
+// combined with the current values, and supplied slice fields are cleared on cur so
+// encoding/json does not decode into stale elements.
+func prepareMerge(mapped map[string]interface{}, cur reflect.Value, cfg *config, path string) error {
+	if cur.Kind() == reflect.Ptr {
+		if cur.IsNil() {
+			return nil
+		}
+		cur = cur.Elem()
+	}
+	if cur.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := jsonFieldLookup(cur.Type())
+	for k, v := range mapped {
+		fi, ok := fields[k]
+		if !ok {
+			continue
+		}
+		fv := cur.Field(fi.Index)
+		ft := fi.FieldType
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch ft.Kind() {
+		case reflect.Struct:
+			if m, ok := v.(map[string]interface{}); ok {
+				if err := prepareMerge(m, fv, cfg, joinFieldPath(path, k)); err != nil {
+					return err
+				}
+			}
+		case reflect.Map:
+			m, ok := v.(map[string]interface{})
+			if !ok || fv.IsNil() {
+				continue
+			}
+			existing, err := toGenericMap(fv.Interface())
+			if err != nil {
+				return &FieldError{Path: joinFieldPath(path, k), Err: err}
+			}
+			mapped[k] = deepMergeMaps(existing, m)
+		case reflect.Slice:
+			arr, ok := v.([]interface{})
+			if !ok {
+				continue
+			}
+			strategy, key := sliceStrategyFor(fi.Tag, cfg)
+			merged, err := mergeSliceValue(fv, arr, strategy, key)
+			if err != nil {
+				return &FieldError{Path: joinFieldPath(path, k), Err: err}
+			}
+			mapped[k] = merged
+		}
+	}
+	return nil
+}
+
+// mergeSliceValue combines the current slice cur with the supplied elements and
+// resets cur so the merged result decodes cleanly.
+func mergeSliceValue(cur reflect.Value, supplied []interface{}, strategy SliceStrategy, key string) ([]interface{}, error) {
+	if cur.Kind() == reflect.Ptr {
+		if cur.IsNil() {
+			// a nil *[]T holds no elements, like an empty slice
+			return supplied, nil
+		}
+		cur = cur.Elem()
+	}
+	if cur.IsNil() || strategy == SliceReplace {
+		cur.Set(reflect.Zero(cur.Type()))
+		return supplied, nil
+	}
+	var existing []interface{}
+	if err := toGeneric(cur.Interface(), &existing); err != nil {
+		return nil, err
+	}
+	cur.Set(reflect.Zero(cur.Type()))
+	if strategy == SliceAppend {
+		return append(existing, supplied...), nil
+	}
+	// SliceMergeByKey
+	index := make(map[string]int, len(existing))
+	for i, e := range existing {
+		if em, ok := e.(map[string]interface{}); ok {
+			if kv, ok := em[key]; ok && kv != nil {
+				index[fmt.Sprint(kv)] = i
+			}
+		}
+	}
+	for _, s := range supplied {
+		sm, ok := s.(map[string]interface{})
+		if ok {
+			if kv, ok := sm[key]; ok && kv != nil {
+				if i, found := index[fmt.Sprint(kv)]; found {
+					existing[i] = deepMergeMaps(existing[i].(map[string]interface{}), sm)
+					continue
+				}
+			}
+		}
+		existing = append(existing, s)
+	}
+	return existing, nil
+}
+
+// deepMergeMaps merges src into dst, recursing where both hold objects; src wins otherwise.
+func deepMergeMaps(dst, src map[string]interface{}) map[string]interface{} {
+	for k, sv := range src {
+		if sm, ok := sv.(map[string]interface{}); ok {
+			if dm, ok := dst[k].(map[string]interface{}); ok {
+				dst[k] = deepMergeMaps(dm, sm)
+				continue
+			}
+		}
+		dst[k] = sv
+	}
+	return dst
+}
+
+// toGeneric re-encodes a typed value into its generic JSON shape.
+func toGeneric(v interface{}, out interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return unmarshalJSONNumbers(b, out)
+}
+
+func toGenericMap(v interface{}) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	err := toGeneric(v, &m)
+	return m, err
+}
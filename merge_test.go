@@ -0,0 +1,85 @@
+package databridge
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type mergeItem struct {
+	ID  int    `json:"id"`
+	Qty int    `json:"qty"`
+	Tag string `json:"tag"`
+}
+
+type mergeConfig struct {
+	Name   string                      `json:"name"`
+	Port   int                         `json:"port"`
+	DB     struct{ Host, User string } `json:"db"`
+	Labels map[string]interface{}      `json:"labels"`
+	Hosts  []string                    `json:"hosts"`
+	Extra  []string                    `json:"extra" databridge:"merge=append"`
+	Items  []mergeItem                 `json:"items" databridge:"merge=key"`
+}
+
+func TestTransformIntoOverlaysSuppliedFields(t *testing.T) {
+	cfg := mergeConfig{Name: "svc", Port: 80, Hosts: []string{"a", "b"}, Extra: []string{"x"}}
+	cfg.DB.Host, cfg.DB.User = "localhost", "root"
+	cfg.Labels = map[string]interface{}{"team": "core", "env": map[string]interface{}{"stage": "dev", "region": "eu"}}
+	cfg.Items = []mergeItem{{ID: 1, Qty: 1, Tag: "keep"}, {ID: 2, Qty: 2}}
+
+	// env-style overlay via form values, then a JSON request overlay
+	if err := TransformInto(&cfg, url.Values{"port": {"8080"}, "db.user": {"app"}}); err != nil {
+		t.Fatalf("form overlay failed: %v", err)
+	}
+	in := `{"labels":{"env":{"stage":"prod"}},"hosts":["c"],"extra":["y"],"items":[{"id":1,"qty":5},{"id":3,"qty":3}]}`
+	if err := TransformInto(&cfg, in, WithKeyNormalization(false)); err != nil {
+		t.Fatalf("json overlay failed: %v", err)
+	}
+	if cfg.Name != "svc" || cfg.Port != 8080 || cfg.DB.Host != "localhost" || cfg.DB.User != "app" {
+		t.Fatalf("scalar/struct merge failed: %+v", cfg)
+	}
+	env := cfg.Labels["env"].(map[string]interface{})
+	if cfg.Labels["team"] != "core" || env["stage"] != "prod" || env["region"] != "eu" {
+		t.Fatalf("map deep merge failed: %+v", cfg.Labels)
+	}
+	if !reflect.DeepEqual(cfg.Hosts, []string{"c"}) || !reflect.DeepEqual(cfg.Extra, []string{"x", "y"}) {
+		t.Fatalf("slice strategies failed: hosts=%v extra=%v", cfg.Hosts, cfg.Extra)
+	}
+	want := []mergeItem{{ID: 1, Qty: 5, Tag: "keep"}, {ID: 2, Qty: 2}, {ID: 3, Qty: 3}}
+	if !reflect.DeepEqual(cfg.Items, want) {
+		t.Fatalf("merge-by-key failed: %+v", cfg.Items)
+	}
+}
+
+func TestMergeRowsWithSliceStrategy(t *testing.T) {
+	rows := []mergeItem{{ID: 1, Qty: 1, Tag: "a"}}
+	if err := TransformInto(&rows, "id,qty\n1,9\n2,2\n", WithSliceStrategy(SliceMergeByKey)); err != nil {
+		t.Fatalf("row merge failed: %v", err)
+	}
+	want := []mergeItem{{ID: 1, Qty: 9, Tag: "a"}, {ID: 2, Qty: 2}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("row merge = %+v", rows)
+	}
+}
+
+func TestMergeNilSlicePointer(t *testing.T) {
+	type P struct {
+		Name string    `json:"name"`
+		Tags *[]string `json:"tags"`
+	}
+	for _, s := range []SliceStrategy{SliceReplace, SliceAppend} {
+		p := P{Name: "keep"}
+		if err := TransformInto(&p, `{"tags":["a"]}`, WithSliceStrategy(s)); err != nil {
+			t.Fatalf("strategy %v: %v", s, err)
+		}
+		if p.Name != "keep" || p.Tags == nil || !reflect.DeepEqual(*p.Tags, []string{"a"}) {
+			t.Fatalf("strategy %v: merged = %+v", s, p)
+		}
+	}
+	tags := []string{"x"}
+	p := P{Tags: &tags}
+	if err := TransformInto(&p, `{"tags":["a"]}`, WithSliceStrategy(SliceAppend)); err != nil || !reflect.DeepEqual(*p.Tags, []string{"x", "a"}) {
+		t.Fatalf("append onto *[]T = %+v err=%v", p.Tags, err)
+	}
+}
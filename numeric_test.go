@@ -0,0 +1,128 @@
+package databridge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStrictNumbersRejectsLossyValues(t *testing.T) {
+	type S struct {
+		Count int    `json:"count"`
+		Size  uint32 `json:"size"`
+		Ratio float64
+	}
+	cases := []struct {
+		in, path, msg string
+	}{
+		{`{"count":3.9}`, "count", "fractional"},
+		{`{"count":"3.9"}`, "count", "fractional"},
+		{`{"size":-1}`, "size", "negative"},
+		{`{"Ratio":9007199254740993}`, "Ratio", "exactly"},
+	}
+	for _, c := range cases {
+		_, err := Transform[S](c.in, WithStrictNumbers(true))
+		var fe *FieldError
+		if !errors.As(err, &fe) || fe.Path != c.path || !strings.Contains(err.Error(), c.msg) {
+			t.Fatalf("%s: expected %q error at %q, got %v", c.in, c.msg, c.path, err)
+		}
+	}
+
+	// without strict numbers the legacy truncation/clamping is kept
+	s, err := Transform[S](`{"count":3.9,"size":-1}`)
+	if err != nil || s.Count != 3 || s.Size != 0 {
+		t.Fatalf("lenient numbers changed: %+v err=%v", s, err)
+	}
+}
+
+func TestNumericOverflowHasFieldPath(t *testing.T) {
+	type Inner struct {
+		Level int8 `json:"level"`
+	}
+	type S struct {
+		Items []Inner `json:"items"`
+		Small uint8   `json:"small"`
+	}
+	_, err := Transform[S](`{"items":[{"level":1},{"level":300}]}`)
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "items[1].level" || !strings.Contains(err.Error(), "overflows int8") {
+		t.Fatalf("expected int8 overflow at items[1].level, got %v", err)
+	}
+	_, err = Transform[S]("small=256")
+	if !errors.As(err, &fe) || fe.Path != "small" {
+		t.Fatalf("expected uint8 overflow at small, got %v", err)
+	}
+}
+
+func TestLargeIDsKeepPrecision(t *testing.T) {
+	type S struct {
+		ID     int64  `json:"id"`
+		UID    uint64 `json:"uid"`
+		IDText string `json:"id_text"`
+	}
+	in := `{"id":9007199254740993,"uid":18446744073709551615,"id_text":1234567890123456789}`
+	s, err := Transform[S](in)
+	if err != nil {
+		t.Fatalf("large ids failed: %v", err)
+	}
+	if s.ID != 9007199254740993 || s.UID != 18446744073709551615 || s.IDText != "1234567890123456789" {
+		t.Fatalf("precision lost: %+v", s)
+	}
+	rows, err := Transform[[]S](`[{"id":9223372036854775807}]`)
+	if err != nil || rows[0].ID != 9223372036854775807 {
+		t.Fatalf("precision lost in array: %+v err=%v", rows, err)
+	}
+}
+
+func TestStrictNumbersSkipsFastPath(t *testing.T) {
+	type S struct {
+		F float64 `json:"f"`
+	}
+	_, err := FromJSONString[S](`{"f":9007199254740993}`, WithStrictNumbers(true))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "f" {
+		t.Fatalf("expected FieldError at f, got %v", err)
+	}
+}
+
+func TestNestedArraysCoerceNumbers(t *testing.T) {
+	rows, _, err := ParseRecords(`{"grid":[[1,2],[3.5]],"deep":[[[{"n":7}]]]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grid := rows[0]["grid"].([]interface{})
+	if grid[0].([]interface{})[1] != int64(2) || grid[1].([]interface{})[0] != 3.5 {
+		t.Fatalf("nested array numbers not coerced: %#v", grid)
+	}
+	deep := rows[0]["deep"].([]interface{})[0].([]interface{})[0].([]interface{})[0].(map[string]interface{})
+	if deep["n"] != int64(7) {
+		t.Fatalf("deeply nested map not coerced: %#v", deep)
+	}
+}
+
+func TestLargeIDsKeepPrecisionInMaps(t *testing.T) {
+	in := `{"id":9007199254740993,"n":2,"f":1.5,"items":[{"id":9007199254740993}]}`
+	var m map[string]interface{}
+	if err := TransformToStructUniversal(in, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["id"] != int64(9007199254740993) || m["n"] != float64(2) || m["f"] != 1.5 {
+		t.Fatalf("map numbers = %#v", m)
+	}
+	if item := m["items"].([]interface{})[0].(map[string]interface{}); item["id"] != int64(9007199254740993) {
+		t.Fatalf("nested id = %#v", item["id"])
+	}
+
+	// the fast path and interface{} outputs too
+	fm, err := FromJSONString[map[string]interface{}](`{"id":9007199254740993}`)
+	if err != nil || fm["id"] != int64(9007199254740993) {
+		t.Fatalf("fast path map = %#v err=%v", fm, err)
+	}
+	var v interface{}
+	if err := TransformToStructUniversal(`{"id":9007199254740993}`, &v); err != nil {
+		t.Fatal(err)
+	}
+	if id := v.(map[string]interface{})["id"]; id != int64(9007199254740993) {
+		t.Fatalf("interface output id = %#v", id)
+	}
+}
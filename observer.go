@@ -0,0 +1,238 @@
+package databridge
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Observer receives events for every transform, e.g. to export metrics or
+// tracing spans. Phases are "read", "parse" (including WithRoot and WithPipeline),
+// "map" (key mapping, hooks and coercion) and "decode"; a transform ends with
+// OnPhaseDone("total", ...) whether it succeeded or not. Rows are the records of
+// CSV input or JSON arrays decoded into a slice or by TransformSeqContext. Methods
+// are called synchronously on the transforming goroutine and ctx is the
+// transform's context.
+//
+// Implementations must be safe for concurrent use when shared across calls.
+// ObserverFuncs adapts plain functions, so an OpenTelemetry or Prometheus
+// exporter only has to supply the events it needs.
+type Observer interface {
+	OnStart(ctx context.Context)
+	OnFormatDetected(ctx context.Context, format string)
+	OnPhaseDone(ctx context.Context, phase string, d time.Duration)
+	OnError(ctx context.Context, phase string, err error)
+	OnRow(ctx context.Context, row int, err error)
+}
+
+// WithObserver reports the events of each transform to o. Observers set on a
+// Bridge and per call all receive events, in the order they were given.
+func WithObserver(o Observer) Option {
+	return func(c *config) {
+		if c.observer == nil {
+			c.observer = o
+			return
+		}
+		c.observer = multiObserver{c.observer, o}
+	}
+}
+
+// ObserverFuncs implements Observer with optional functions; nil fields ignore
+// their event.
+//
+// Example (Prometheus):
+//
+//	obs := databridge.ObserverFuncs{
+//		PhaseDone: func(_ context.Context, phase string, d time.Duration) {
+//			phaseSeconds.WithLabelValues(phase).Observe(d.Seconds())
+//		},
+//	}
+//	b := databridge.NewBridge(databridge.WithObserver(obs))
+type ObserverFuncs struct {
+	Start          func(ctx context.Context)
+	FormatDetected func(ctx context.Context, format string)
+	PhaseDone      func(ctx context.Context, phase string, d time.Duration)
+	Error          func(ctx context.Context, phase string, err error)
+	Row            func(ctx context.Context, row int, err error)
+}
+
+func (f ObserverFuncs) OnStart(ctx context.Context) {
+	if f.Start != nil {
+		f.Start(ctx)
+	}
+}
+
+func (f ObserverFuncs) OnFormatDetected(ctx context.Context, format string) {
+	if f.FormatDetected != nil {
+		f.FormatDetected(ctx, format)
+	}
+}
+
+func (f ObserverFuncs) OnPhaseDone(ctx context.Context, phase string, d time.Duration) {
+	if f.PhaseDone != nil {
+		f.PhaseDone(ctx, phase, d)
+	}
+}
+
+func (f ObserverFuncs) OnError(ctx context.Context, phase string, err error) {
+	if f.Error != nil {
+		f.Error(ctx, phase, err)
+	}
+}
+
+func (f ObserverFuncs) OnRow(ctx context.Context, row int, err error) {
+	if f.Row != nil {
+		f.Row(ctx, row, err)
+	}
+}
+
+type multiObserver []Observer
+
+func (m multiObserver) OnStart(ctx context.Context) {
+	for _, o := range m {
+		o.OnStart(ctx)
+	}
+}
+
+func (m multiObserver) OnFormatDetected(ctx context.Context, format string) {
+	for _, o := range m {
+		o.OnFormatDetected(ctx, format)
+	}
+}
+
+func (m multiObserver) OnPhaseDone(ctx context.Context, phase string, d time.Duration) {
+	for _, o := range m {
+		o.OnPhaseDone(ctx, phase, d)
+	}
+}
+
+func (m multiObserver) OnError(ctx context.Context, phase string, err error) {
+	for _, o := range m {
+		o.OnError(ctx, phase, err)
+	}
+}
+
+func (m multiObserver) OnRow(ctx context.Context, row int, err error) {
+	for _, o := range m {
+		o.OnRow(ctx, row, err)
+	}
+}
+
+// Counters is an in-memory Observer counting transforms, formats, rows and
+// errors and summing phase durations. The zero value is ready to use and it is
+// safe for concurrent use.
+type Counters struct {
+	mu sync.Mutex
+	s  CounterSnapshot
+}
+
+// CounterSnapshot is a copy of the values of a Counters.
+type CounterSnapshot struct {
+	Transforms int64
+	Errors     int64 // failed transforms
+	Rows       int64
+	RowErrors  int64
+	Formats    map[string]int64         // transforms per detected format
+	PhaseTime  map[string]time.Duration // total time per phase
+	PhaseCount map[string]int64
+	ErrorPhase map[string]int64 // failed transforms per phase
+}
+
+func (c *Counters) OnStart(context.Context) {
+	c.mu.Lock()
+	c.s.Transforms++
+	c.mu.Unlock()
+}
+
+func (c *Counters) OnFormatDetected(_ context.Context, format string) {
+	c.mu.Lock()
+	incr(&c.s.Formats, format, 1)
+	c.mu.Unlock()
+}
+
+func (c *Counters) OnPhaseDone(_ context.Context, phase string, d time.Duration) {
+	c.mu.Lock()
+	if c.s.PhaseTime == nil {
+		c.s.PhaseTime = map[string]time.Duration{}
+	}
+	c.s.PhaseTime[phase] += d
+	incr(&c.s.PhaseCount, phase, 1)
+	c.mu.Unlock()
+}
+
+func (c *Counters) OnError(_ context.Context, phase string, _ error) {
+	c.mu.Lock()
+	c.s.Errors++
+	incr(&c.s.ErrorPhase, phase, 1)
+	c.mu.Unlock()
+}
+
+func (c *Counters) OnRow(_ context.Context, _ int, err error) {
+	c.mu.Lock()
+	c.s.Rows++
+	if err != nil {
+		c.s.RowErrors++
+	}
+	c.mu.Unlock()
+}
+
+// Snapshot returns a copy of the current values.
+func (c *Counters) Snapshot() CounterSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.s
+	s.Formats = copyMap(c.s.Formats)
+	s.PhaseTime = copyMap(c.s.PhaseTime)
+	s.PhaseCount = copyMap(c.s.PhaseCount)
+	s.ErrorPhase = copyMap(c.s.ErrorPhase)
+	return s
+}
+
+func incr(m *map[string]int64, k string, n int64) {
+	if *m == nil {
+		*m = map[string]int64{}
+	}
+	(*m)[k] += n
+}
+
+func copyMap[V any](m map[string]V) map[string]V {
+	out := make(map[string]V, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// observeStart reports the start of a transform.
+func (c *config) observeStart() {
+	if c.observer == nil {
+		return
+	}
+	c.started = time.Now()
+	c.observer.OnStart(c.ctx)
+}
+
+// enterPhase ends the current phase, reporting its duration, and starts phase.
+func (c *config) enterPhase(phase string) {
+	if c.observer == nil || c.phase == phase {
+		return
+	}
+	now := time.Now()
+	if c.phase != "" {
+		c.observer.OnPhaseDone(c.ctx, c.phase, now.Sub(c.phaseStart))
+	}
+	c.phase, c.phaseStart = phase, now
+}
+
+// observeDone ends the current phase and the transform, reporting err.
+func (c *config) observeDone(err error) {
+	if c.observer == nil {
+		return
+	}
+	failed := c.phase
+	c.enterPhase("")
+	c.observer.OnPhaseDone(c.ctx, "total", time.Since(c.started))
+	if err != nil {
+		c.observer.OnError(c.ctx, failed, err)
+	}
+}
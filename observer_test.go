@@ -0,0 +1,85 @@
+package databridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestObserverCounters(t *testing.T) {
+	type Row struct {
+		Name string `json:"name"`
+		Age  int8   `json:"age"`
+	}
+	var c Counters
+	b := NewBridge(WithObserver(&c))
+
+	var rows []Row
+	if err := b.TransformToStructUniversal("name,age\na,1\nb,2\n", &rows); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	var r Row
+	if err := b.TransformToStructUniversal(`{"name":"x","age":1000}`, &r); err == nil {
+		t.Fatalf("expected overflow error")
+	}
+	for range TransformSeqContext[Row](context.Background(), "name,age\na,1\nb,300\nc,3\n", WithObserver(&c)) {
+	}
+
+	s := c.Snapshot()
+	if s.Transforms != 3 || s.Errors != 1 || s.ErrorPhase["map"] != 1 {
+		t.Fatalf("transforms=%d errors=%d by phase=%v", s.Transforms, s.Errors, s.ErrorPhase)
+	}
+	if s.Rows != 5 || s.RowErrors != 1 {
+		t.Fatalf("rows=%d rowErrors=%d", s.Rows, s.RowErrors)
+	}
+	if s.Formats["csv"] != 2 || s.Formats["json"] != 1 {
+		t.Fatalf("formats = %v", s.Formats)
+	}
+	if s.PhaseCount["total"] != 3 || s.PhaseCount["read"] != 2 || s.PhaseCount["decode"] != 1 || s.PhaseCount["map"] != 3 {
+		t.Fatalf("phase counts = %v", s.PhaseCount)
+	}
+	// snapshots are copies
+	s.Formats["csv"] = 99
+	if c.Snapshot().Formats["csv"] != 2 {
+		t.Fatalf("snapshot shares state with Counters")
+	}
+}
+
+func TestObserverFuncs(t *testing.T) {
+	type Doc struct {
+		Name string `json:"name"`
+	}
+	var events []string
+	rec := func(prefix string) ObserverFuncs {
+		return ObserverFuncs{
+			Start:          func(context.Context) { events = append(events, prefix+"start") },
+			FormatDetected: func(_ context.Context, f string) { events = append(events, prefix+"format:"+f) },
+			PhaseDone: func(_ context.Context, p string, d time.Duration) {
+				if d < 0 {
+					t.Errorf("negative duration for %s", p)
+				}
+				events = append(events, prefix+"done:"+p)
+			},
+			Error: func(_ context.Context, p string, err error) { events = append(events, prefix+"error:"+p) },
+		}
+	}
+	b := NewBridge(WithObserver(rec("")))
+	var d Doc
+	if err := b.TransformToStructUniversal("name=x", &d, WithObserver(rec("call:"))); err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	want := "start call:start done:read call:done:read format:form call:format:form done:parse call:done:parse " +
+		"done:map call:done:map done:decode call:done:decode done:total call:done:total"
+	if got := strings.Join(events, " "); got != want {
+		t.Fatalf("events:\n got %s\nwant %s", got, want)
+	}
+
+	events = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = TransformContext(ctx, "name=x", &d, WithObserver(rec("")))
+	if got := strings.Join(events, " "); got != "start done:read done:total error:read" {
+		t.Fatalf("canceled events: %s", got)
+	}
+}
@@ -5,56 +5,97 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"strings"
 
 	yaml "gopkg.in/yaml.v3"
 )
 
-// parseBytesDetect tries formats in order: JSON -> form -> YAML -> XML -> CSV -> fallback string
-// Returns either a single map (map[string]interface{}) or an array ([]map[string]interface{}) for multi-row formats (CSV)
+// parseBytesDetect parses b with parseBytesFormat and reports the detected format.
 func parseBytesDetect(b []byte, cfg *config) (map[string]interface{}, []map[string]interface{}, error) {
+	format, m, rows, err := parseBytesFormat(b, cfg)
+	if err == nil {
+		cfg.formatDetected(format)
+	}
+	return m, rows, err
+}
+
+// parseBytesFormat parses b with parseFormats after checking JSON against the
+// configured limits. parseFormats checks forms, YAML and XML before building
+// their values; everything but JSON is checked once more when parsed.
+func parseBytesFormat(b []byte, cfg *config) (string, map[string]interface{}, []map[string]interface{}, error) {
+	likelyJSON := isLikelyJSON(b)
+	if likelyJSON {
+		if err := cfg.scanJSONLimits(b); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	format, m, rows, err := parseFormats(b, cfg)
+	if err == nil && !likelyJSON {
+		err = cfg.checkParsedLimits(m, rows)
+	}
+	return format, m, rows, err
+}
+
+// parseFormats tries formats in order: JSON -> form -> YAML -> XML -> CSV -> fallback string
+// Returns the name of the format and either a single map (map[string]interface{}) or an array ([]map[string]interface{}) for multi-row formats (CSV)
+func parseFormats(b []byte, cfg *config) (string, map[string]interface{}, []map[string]interface{}, error) {
 	trim := bytes.TrimSpace(b)
 	if len(trim) == 0 {
-		return map[string]interface{}{}, nil, nil
+		return "empty", map[string]interface{}{}, nil, nil
 	}
 
 	// JSON (object)
 	var jm map[string]interface{}
-	if json.Unmarshal(trim, &jm) == nil {
-		return coerceNumbersInMap(jm, cfg), nil, nil
+	if unmarshalJSONNumbers(trim, &jm) == nil {
+		return "json", coerceNumbersInMap(jm, cfg), nil, nil
 	}
 	// JSON (array of objects)
 	var jarr []map[string]interface{}
-	if json.Unmarshal(trim, &jarr) == nil {
+	if unmarshalJSONNumbers(trim, &jarr) == nil {
 		// Coerce numbers within each object
 		if cfg != nil {
 			for i := range jarr {
 				jarr[i] = coerceNumbersInMap(jarr[i], cfg)
 			}
 		}
-		return nil, jarr, nil
+		return "json", nil, jarr, nil
 	}
 
 	// form (heuristic)
 	str := string(trim)
 	if looksLikeForm(str) {
 		if vals, err := url.ParseQuery(str); err == nil {
-			return formValuesToMapWithDots(vals, cfg), nil, nil
+			if err := cfg.checkFormLimits(vals); err != nil {
+				return "", nil, nil, err
+			}
+			cfg.keepFormText(vals)
+			return "form", formValuesToMapWithDots(vals, cfg), nil, nil
 		}
 	}
 
 	// YAML
 	if cfg.EnableYAML {
-		var yv interface{}
-		if err := yaml.Unmarshal(trim, &yv); err == nil {
-			converted := convertYAMLToMap(yv)
-			return coerceNumbersInMap(converted, cfg), nil, nil
+		var node yaml.Node
+		if err := yaml.Unmarshal(trim, &node); err == nil {
+			if err := cfg.checkYAMLLimits(&node, 0); err != nil {
+				return "", nil, nil, err
+			}
+			var yv interface{}
+			if err := node.Decode(&yv); err == nil {
+				converted := convertYAMLToMap(yv)
+				return "yaml", coerceNumbersInMap(converted, cfg), nil, nil
+			}
 		}
 	}
 
 	// XML (best-effort)
+	if err := cfg.scanXMLLimits(trim); err != nil {
+		return "", nil, nil, err
+	}
 	var xi interface{}
 	if xml.Unmarshal(trim, &xi) == nil {
 		var any interface{}
@@ -62,7 +103,7 @@ func parseBytesDetect(b []byte, cfg *config) (map[string]interface{}, []map[stri
 			if j, merr := json.Marshal(any); merr == nil {
 				var mm map[string]interface{}
 				if json.Unmarshal(j, &mm) == nil {
-					return coerceNumbersInMap(mm, cfg), nil, nil
+					return "xml", coerceNumbersInMap(mm, cfg), nil, nil
 				}
 			}
 		}
@@ -70,13 +111,32 @@ func parseBytesDetect(b []byte, cfg *config) (map[string]interface{}, []map[stri
 
 	// CSV
 	if looksLikeCSV(str) {
-		rows, cerr := parseCSVToMaps(str)
+		rows, cerr := parseCSVToMaps(str, cfg)
+		var canceled *CanceledError
+		if errors.Is(cerr, ErrLimitExceeded) || errors.As(cerr, &canceled) {
+			return "", nil, nil, cerr
+		}
 		if cerr == nil && len(rows) > 0 {
-			return nil, rows, nil
+			return "csv", nil, rows, nil
 		}
 	}
 
-	return map[string]interface{}{"value": str}, nil, nil
+	return "text", map[string]interface{}{"value": str}, nil, nil
+}
+
+// unmarshalJSONNumbers is json.Unmarshal with UseNumber, so integers keep full
+// 64-bit precision instead of passing through float64.
+func unmarshalJSONNumbers(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	// reject trailing data, as json.Unmarshal does
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("databridge: invalid data after top-level JSON value")
+	}
+	return nil
 }
 
 func looksLikeCSV(s string) bool {
@@ -95,26 +155,45 @@ func looksLikeForm(s string) bool {
 }
 
 // parseCSVToMaps parses CSV assuming first row header and returns slice of row maps.
-func parseCSVToMaps(s string) ([]map[string]interface{}, error) {
+// Rows are read one at a time so MaxCSVRows stops parsing at the first extra row.
+func parseCSVToMaps(s string, cfg *config) ([]map[string]interface{}, error) {
 	r := csv.NewReader(strings.NewReader(s))
 	// Allow variable number of fields per record; we'll align using the header
 	r.FieldsPerRecord = -1
-	records, err := r.ReadAll()
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("databridge: csv read error: %w", err)
 	}
-	if len(records) == 0 {
-		return nil, nil
+	if err := cfg.checkCount(true, len(header)); err != nil {
+		return nil, err
 	}
-	header := records[0]
 	if len(header) > 0 && len(header[0]) > 0 {
 		// Strip UTF-8 BOM if present in the first header cell
 		header[0] = strings.TrimPrefix(header[0], "\ufeff")
 	}
-	out := make([]map[string]interface{}, 0, len(records)-1)
-	for i := 1; i < len(records); i++ {
-		row := records[i]
+	var out, text []map[string]interface{}
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("databridge: csv read error: %w", err)
+		}
+		if err := cfg.checkContext("parse", len(out)); err != nil {
+			return nil, err
+		}
+		if cfg.MaxCSVRows > 0 && len(out) == cfg.MaxCSVRows {
+			return nil, &LimitError{Limit: "csv rows", Max: int64(cfg.MaxCSVRows)}
+		}
 		m := make(map[string]interface{}, len(header))
+		var tm map[string]interface{}
+		if cfg.schema != nil {
+			tm = make(map[string]interface{}, len(header))
+		}
 		for j, h := range header {
 			var val string
 			if j < len(row) {
@@ -122,9 +201,16 @@ func parseCSVToMaps(s string) ([]map[string]interface{}, error) {
 			} else {
 				val = ""
 			}
-			m[h] = stringToBestType(val)
+			m[h] = stringToBestType(val, cfg)
+			if tm != nil {
+				tm[h] = val
+			}
 		}
 		out = append(out, normalizeDotsToNested(m))
+		if tm != nil {
+			text = append(text, normalizeDotsToNested(tm))
+		}
 	}
+	cfg.textRows = text
 	return out, nil
 }
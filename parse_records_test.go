@@ -0,0 +1,14 @@
+package databridge
+
+import "testing"
+
+func TestParseRecords(t *testing.T) {
+	rows, format, err := ParseRecords("id,name\n1,Ada\n2,Bob\n")
+	if err != nil || format != "csv" || len(rows) != 2 || rows[1]["id"] != int64(2) || rows[0]["name"] != "Ada" {
+		t.Fatalf("csv: %v %q %v", rows, format, err)
+	}
+	rows, format, err = ParseRecords(`{"data":{"id":7}}`, WithRoot("data"))
+	if err != nil || format != "json" || len(rows) != 1 || rows[0]["id"] != int64(7) {
+		t.Fatalf("json: %v %q %v", rows, format, err)
+	}
+}
@@ -0,0 +1,387 @@
+package databridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPatch    = errors.New("databridge: invalid patch")
+	ErrPatchTestFailed = errors.New("databridge: patch test failed")
+)
+
+// ApplyMergePatch applies an RFC 7386 JSON Merge Patch to doc and returns the
+// patched copy. The patch may be in any format TransformToStructUniversal
+// accepts (JSON, form, YAML with WithYAML, a map, ...); its keys are matched
+// against T's fields with the usual normalization, null removes a field (resets
+// it to its zero value), and values are coerced to the field types.
+//
+// Example:
+//
+//	user, err := databridge.ApplyMergePatch(user, `{"Email":"ada@example.com","nickname":null}`)
+func ApplyMergePatch[T any](doc T, patch any, opts ...Option) (T, error) {
+	var zero T
+	cfg := newConfig(opts)
+	var target interface{}
+	if err := toGeneric(doc, &target); err != nil {
+		return zero, fmt.Errorf("databridge: encode document: %w", err)
+	}
+	pm, parr, err := parseInput(patch, cfg)
+	if err != nil {
+		return zero, err
+	}
+	if parr != nil {
+		return zero, fmt.Errorf("%w: merge patch must be an object", ErrInvalidPatch)
+	}
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	mapped, unmatched, _ := mapNestedValue(pm, typ, cfg)
+	if cfg.Strict && len(unmatched) > 0 {
+		return zero, cfg.unknownFieldsError(typ, unmatched)
+	}
+	return decodePatched[T](mergePatchValue(target, mapped), opts)
+}
+
+// mergePatchValue implements the RFC 7386 MergePatch algorithm on generic values.
+func mergePatchValue(target, patch interface{}) interface{} {
+	pm, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]interface{})
+	if !ok {
+		tm = map[string]interface{}{}
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+			continue
+		}
+		tm[k] = mergePatchValue(tm[k], v)
+	}
+	return tm
+}
+
+// patchOp is one RFC 6902 operation.
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch (add, remove, replace, move,
+// copy, test) to doc and returns the patched copy. Path segments are JSON
+// Pointers matched against T's fields with the usual key normalization, so
+// "/UserName" addresses a field tagged `json:"user_name"`; values are coerced to
+// the field types. Failures are *FieldError values whose Path is the pointer of
+// the failing operation, wrapping ErrInvalidPatch or ErrPatchTestFailed.
+func ApplyJSONPatch[T any](doc T, ops []byte, opts ...Option) (T, error) {
+	var zero T
+	cfg := newConfig(opts)
+	var target interface{}
+	if err := toGeneric(doc, &target); err != nil {
+		return zero, fmt.Errorf("databridge: encode document: %w", err)
+	}
+	var list []patchOp
+	if err := json.Unmarshal(ops, &list); err != nil {
+		return zero, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	for i, op := range list {
+		if op.Path == nil {
+			return zero, fmt.Errorf("%w: operation %d (%s) has no path", ErrInvalidPatch, i, op.Op)
+		}
+		var err error
+		target, err = applyPatchOp(target, typ, op, cfg)
+		if err != nil {
+			return zero, &FieldError{Path: *op.Path, Err: fmt.Errorf("operation %d (%s): %w", i, op.Op, err)}
+		}
+	}
+	return decodePatched[T](target, opts)
+}
+
+// decodePatched decodes a patched generic document into T, applying coercion.
+func decodePatched[T any](v interface{}, opts []Option) (T, error) {
+	var zero T
+	b, err := json.Marshal(v)
+	if err != nil {
+		return zero, fmt.Errorf("databridge: encode patched document: %w", err)
+	}
+	return Transform[T](b, opts...)
+}
+
+func applyPatchOp(doc interface{}, typ reflect.Type, op patchOp, cfg *config) (interface{}, error) {
+	tokens, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+	value := func(ft reflect.Type) (interface{}, error) {
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		}
+		var v interface{}
+		if err := unmarshalJSONNumbers(op.Value, &v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		return preparePatchValue(v, ft, cfg), nil
+	}
+	switch op.Op {
+	case "add", "replace":
+		return patchAt(doc, typ, tokens, cfg, func(parent interface{}, tok string, ft reflect.Type) (interface{}, error) {
+			v, err := value(ft)
+			if err != nil {
+				return nil, err
+			}
+			return setChild(parent, tok, v, op.Op == "add")
+		})
+	case "remove":
+		return patchAt(doc, typ, tokens, cfg, removeChild)
+	case "test":
+		_, err := patchAt(doc, typ, tokens, cfg, func(parent interface{}, tok string, ft reflect.Type) (interface{}, error) {
+			want, err := value(ft)
+			if err != nil {
+				return nil, err
+			}
+			got, err := getChild(parent, tok)
+			if err != nil {
+				return nil, err
+			}
+			if !patchValuesEqual(got, want, ft, cfg) {
+				return nil, ErrPatchTestFailed
+			}
+			return parent, nil
+		})
+		return doc, err
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%w: missing from", ErrInvalidPatch)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" && len(tokens) > len(from) && hasPointerPrefix(tokens, from) {
+			return nil, fmt.Errorf("%w: cannot move a value into itself", ErrInvalidPatch)
+		}
+		var moved interface{}
+		doc, err = patchAt(doc, typ, from, cfg, func(parent interface{}, tok string, _ reflect.Type) (interface{}, error) {
+			v, err := getChild(parent, tok)
+			if err != nil {
+				return nil, err
+			}
+			if op.Op == "copy" {
+				if err := toGeneric(v, &moved); err != nil {
+					return nil, err
+				}
+				return parent, nil
+			}
+			moved = v
+			return removeChild(parent, tok, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return patchAt(doc, typ, tokens, cfg, func(parent interface{}, tok string, _ reflect.Type) (interface{}, error) {
+			return setChild(parent, tok, moved, true)
+		})
+	}
+	return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+}
+
+// hasPointerPrefix reports whether the decoded pointer tokens start with prefix.
+func hasPointerPrefix(tokens, prefix []string) bool {
+	if len(prefix) > len(tokens) {
+		return false
+	}
+	for i, tok := range prefix {
+		if tokens[i] != tok {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, p)
+	}
+	parts := strings.Split(p[1:], "/")
+	for i, t := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return parts, nil
+}
+
+// patchAt walks doc (whose Go shape is typ) along tokens and calls leaf with the
+// parent container of the last token, returning doc with the updated parent.
+// Tokens addressing struct fields are resolved to JSON names with key normalization.
+func patchAt(doc interface{}, typ reflect.Type, tokens []string, cfg *config, leaf func(parent interface{}, tok string, ft reflect.Type) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: the whole document cannot be patched", ErrInvalidPatch)
+	}
+	tok, ft, err := resolvePatchToken(doc, typ, tokens[0], cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return leaf(doc, tok, ft)
+	}
+	child, err := getChild(doc, tok)
+	if err != nil {
+		return nil, err
+	}
+	nc, err := patchAt(child, ft, tokens[1:], cfg, leaf)
+	if err != nil {
+		return nil, err
+	}
+	return setChild(doc, tok, nc, false)
+}
+
+// resolvePatchToken maps a pointer token to the key used in the generic document
+// and returns the Go type of the addressed value.
+func resolvePatchToken(container interface{}, typ reflect.Type, tok string, cfg *config) (string, reflect.Type, error) {
+	anyType := reflect.TypeOf((*interface{})(nil)).Elem()
+	if typ == nil {
+		typ = anyType
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		if _, isMap := container.(map[string]interface{}); !isMap {
+			return "", nil, fmt.Errorf("%w: %q is not an object", ErrInvalidPatch, tok)
+		}
+		norm := tok
+		if cfg.KeyNormalizer != nil {
+			norm = cfg.KeyNormalizer(tok)
+		}
+		fi, ok := cfg.fieldLookup(typ)[norm]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unknown field %q", ErrInvalidPatch, tok)
+		}
+		return fi.JSONName, fi.FieldType, nil
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return tok, typ.Elem(), nil
+	}
+	return tok, anyType, nil
+}
+
+func getChild(container interface{}, tok string) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		v, ok := c[tok]
+		if !ok {
+			return nil, fmt.Errorf("%w: path not found at %q", ErrInvalidPatch, tok)
+		}
+		return v, nil
+	case []interface{}:
+		i, err := arrayIndex(tok, len(c), false)
+		if err != nil {
+			return nil, err
+		}
+		return c[i], nil
+	}
+	return nil, fmt.Errorf("%w: cannot address %q in a scalar", ErrInvalidPatch, tok)
+}
+
+// setChild sets container[tok] = v. For arrays, insert selects add semantics
+// (insert before the index, "-" appends) over replacing the element.
+func setChild(container interface{}, tok string, v interface{}, insert bool) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		if !insert {
+			if _, ok := c[tok]; !ok {
+				return nil, fmt.Errorf("%w: path not found at %q", ErrInvalidPatch, tok)
+			}
+		}
+		c[tok] = v
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(tok, len(c), insert)
+		if err != nil {
+			return nil, err
+		}
+		if !insert {
+			c[i] = v
+			return c, nil
+		}
+		c = append(c, nil)
+		copy(c[i+1:], c[i:])
+		c[i] = v
+		return c, nil
+	case nil:
+		// absent optional object (e.g. a nil pointer field): create it
+		if insert {
+			return map[string]interface{}{tok: v}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: cannot set %q in a scalar", ErrInvalidPatch, tok)
+}
+
+func removeChild(container interface{}, tok string, _ reflect.Type) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		if _, ok := c[tok]; !ok {
+			return nil, fmt.Errorf("%w: path not found at %q", ErrInvalidPatch, tok)
+		}
+		delete(c, tok)
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(tok, len(c), false)
+		if err != nil {
+			return nil, err
+		}
+		return append(c[:i], c[i+1:]...), nil
+	}
+	return nil, fmt.Errorf("%w: cannot remove %q from a scalar", ErrInvalidPatch, tok)
+}
+
+// arrayIndex parses an array token; with insert, "-" and len are valid positions.
+func arrayIndex(tok string, n int, insert bool) (int, error) {
+	if insert && tok == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (tok != "0" && strings.HasPrefix(tok, "0")) {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, tok)
+	}
+	if i > n || (!insert && i == n) {
+		return 0, fmt.Errorf("%w: array index %d out of range", ErrInvalidPatch, i)
+	}
+	return i, nil
+}
+
+// preparePatchValue normalizes and maps the keys of an operation value to the
+// JSON names of the addressed type.
+func preparePatchValue(v interface{}, ft reflect.Type, cfg *config) interface{} {
+	mapped, _, _ := mapNestedValue(v, ft, cfg)
+	return mapped
+}
+
+// patchValuesEqual compares two generic values after coercion to ft.
+func patchValuesEqual(a, b interface{}, ft reflect.Type, cfg *config) bool {
+	ca, errA := coerceValueForType(a, ft, fieldTag{}, cfg, "")
+	cb, errB := coerceValueForType(b, ft, fieldTag{}, cfg, "")
+	if errA != nil || errB != nil {
+		ca, cb = a, b
+	}
+	ja, errA := json.Marshal(ca)
+	jb, errB := json.Marshal(cb)
+	if errA != nil || errB != nil {
+		return false
+	}
+	var va, vb interface{}
+	if unmarshalJSONNumbers(ja, &va) != nil || unmarshalJSONNumbers(jb, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
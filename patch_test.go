@@ -0,0 +1,137 @@
+package databridge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type patchAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type patchUser struct {
+	Name     string            `json:"name"`
+	Email    string            `json:"email"`
+	Age      int               `json:"age"`
+	Nickname *string           `json:"nickname"`
+	Tags     []string          `json:"tags"`
+	Address  patchAddress      `json:"address"`
+	Meta     map[string]string `json:"meta"`
+}
+
+func newPatchUser() patchUser {
+	nick := "ace"
+	return patchUser{
+		Name:     "Ada",
+		Email:    "ada@old.example",
+		Age:      36,
+		Nickname: &nick,
+		Tags:     []string{"a", "b"},
+		Address:  patchAddress{City: "London", Zip: "N1"},
+		Meta:     map[string]string{"team": "core"},
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	doc := newPatchUser()
+	got, err := ApplyMergePatch(doc, `{"E-mail":"ada@new.example","AGE":"37","nickname":null,"address":{"City":"Paris"}}`)
+	if err != nil {
+		t.Fatalf("merge patch failed: %v", err)
+	}
+	want := newPatchUser()
+	want.Email, want.Age, want.Nickname, want.Address.City = "ada@new.example", 37, nil, "Paris"
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("merge patch = %+v, want %+v", got, want)
+	}
+	if doc.Email != "ada@old.example" {
+		t.Fatalf("original document modified: %+v", doc)
+	}
+
+	// non-JSON patch formats and strict unknown fields
+	got, err = ApplyMergePatch(doc, "age=40&address.zip=75001")
+	if err != nil || got.Age != 40 || got.Address.Zip != "75001" || got.Address.City != "London" {
+		t.Fatalf("form merge patch = %+v err=%v", got, err)
+	}
+	if _, err := ApplyMergePatch(doc, `{"bogus":1}`, WithStrict(true)); err == nil {
+		t.Fatalf("expected strict error for unknown field")
+	}
+	if _, err := ApplyMergePatch(doc, `[{"age":1}]`); !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("expected ErrInvalidPatch for array patch, got %v", err)
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := newPatchUser()
+	ops := `[
+		{"op":"test","path":"/Age","value":"36"},
+		{"op":"replace","path":"/E_Mail","value":"ada@new.example"},
+		{"op":"add","path":"/tags/1","value":"x"},
+		{"op":"add","path":"/tags/-","value":"z"},
+		{"op":"remove","path":"/nickname"},
+		{"op":"copy","from":"/address/city","path":"/meta/city"},
+		{"op":"move","from":"/address/zip","path":"/name"}
+	]`
+	got, err := ApplyJSONPatch(doc, []byte(ops))
+	if err != nil {
+		t.Fatalf("json patch failed: %v", err)
+	}
+	want := newPatchUser()
+	want.Email, want.Nickname, want.Name = "ada@new.example", nil, "N1"
+	want.Tags = []string{"a", "x", "b", "z"}
+	want.Address.Zip = ""
+	want.Meta = map[string]string{"team": "core", "city": "London"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("json patch = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	doc := newPatchUser()
+	cases := []struct {
+		ops  string
+		path string
+		err  error
+	}{
+		{`[{"op":"test","path":"/age","value":99}]`, "/age", ErrPatchTestFailed},
+		{`[{"op":"replace","path":"/missing","value":1}]`, "/missing", ErrInvalidPatch},
+		{`[{"op":"remove","path":"/tags/5"}]`, "/tags/5", ErrInvalidPatch},
+		{`[{"op":"frobnicate","path":"/age"}]`, "/age", ErrInvalidPatch},
+		{`[{"op":"move","from":"/address","path":"/address/city"}]`, "/address/city", ErrInvalidPatch},
+		{`[{"op":"move","from":"/meta/a~1b","path":"/meta/a~1b/c"}]`, "/meta/a~1b/c", ErrInvalidPatch},
+		{`[{"op":"move","from":"","path":"/address"}]`, "/address", ErrInvalidPatch},
+	}
+	for _, c := range cases {
+		_, err := ApplyJSONPatch(doc, []byte(c.ops))
+		var fe *FieldError
+		if !errors.As(err, &fe) || fe.Path != c.path || !errors.Is(err, c.err) {
+			t.Fatalf("%s: got %v, want FieldError at %s wrapping %v", c.ops, err, c.path, c.err)
+		}
+	}
+	// a key that merely starts with the source key is not inside it
+	doc.Meta["a"] = "x"
+	moved, err := ApplyJSONPatch(doc, []byte(`[{"op":"move","from":"/meta/a","path":"/meta/ab"}]`))
+	if err != nil || moved.Meta["ab"] != "x" {
+		t.Fatalf("move to sibling key: %+v, %v", moved.Meta, err)
+	}
+	if _, err := ApplyJSONPatch(doc, []byte(`{"op":"add"}`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("expected ErrInvalidPatch for non-array patch, got %v", err)
+	}
+}
+
+func TestPatchKeepsUntouchedNilPointers(t *testing.T) {
+	type O struct {
+		Count int     `json:"count"`
+		Nick  *string `json:"nick"`
+		Next  *O      `json:"next"`
+	}
+	got, err := ApplyMergePatch(O{Count: 3}, `{"count":5}`)
+	if err != nil || got.Count != 5 || got.Nick != nil || got.Next != nil {
+		t.Fatalf("merge patch = %+v err=%v, want untouched nil pointers", got, err)
+	}
+	got, err = ApplyJSONPatch(O{Count: 3}, []byte(`[{"op":"replace","path":"/count","value":5}]`))
+	if err != nil || got.Count != 5 || got.Nick != nil || got.Next != nil {
+		t.Fatalf("json patch = %+v err=%v, want untouched nil pointers", got, err)
+	}
+}
@@ -0,0 +1,212 @@
+package databridge
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a `databridge:"path=..."` tag.
+type pathSegment struct {
+	Key      string // object key, or array index when the key is numeric
+	Wildcard bool   // every element of an array or every value of an object
+}
+
+// parsePath parses a field path in one of three syntaxes:
+//
+//	data.attributes.name       dotted, numeric segments index arrays (items.0.id)
+//	/data/attributes/name      JSON Pointer (RFC 6901)
+//	$.data.items[*].name       JSONPath subset: .key, ['key'], [0], [*] and .*
+//
+// Dotted paths may use the JSONPath bracket forms too (items[0].id).
+func parsePath(p string) []pathSegment {
+	p = strings.TrimSpace(p)
+	if strings.HasPrefix(p, "/") {
+		var segs []pathSegment
+		for _, t := range strings.Split(p[1:], "/") {
+			segs = append(segs, pathSegment{Key: strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")})
+		}
+		return segs
+	}
+	p = strings.TrimPrefix(strings.TrimPrefix(p, "$"), ".")
+	var (
+		segs []pathSegment
+		cur  strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			segs = append(segs, pathSegment{Key: cur.String(), Wildcard: cur.String() == "*"})
+			cur.Reset()
+		}
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '.':
+			flush()
+		case '[':
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 { // unterminated: keep the rest literally
+				cur.WriteString(p[i:])
+				i = len(p)
+				continue
+			}
+			flush()
+			inner := p[i+1 : i+end]
+			if q := strings.Trim(inner, `'"`); len(q) != len(inner) {
+				segs = append(segs, pathSegment{Key: q})
+			} else {
+				segs = append(segs, pathSegment{Key: inner, Wildcard: inner == "*"})
+			}
+			i += end
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return segs
+}
+
+// resolvePath looks up segs in v, normalizing object keys with normalize (when
+// non-nil) so paths match the normalized intermediate map. A wildcard collects the
+// matches into a slice, skipping elements where the rest of the path is missing.
+func resolvePath(v interface{}, segs []pathSegment, normalize func(string) string) (interface{}, bool) {
+	if len(segs) == 0 {
+		return v, true
+	}
+	seg, rest := segs[0], segs[1:]
+	if seg.Wildcard {
+		var elems []interface{}
+		switch c := v.(type) {
+		case []interface{}:
+			elems = c
+		case map[string]interface{}:
+			keys := make([]string, 0, len(c))
+			for k := range c {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				elems = append(elems, c[k])
+			}
+		default:
+			return nil, false
+		}
+		out := make([]interface{}, 0, len(elems))
+		for _, e := range elems {
+			if rv, ok := resolvePath(e, rest, normalize); ok {
+				out = append(out, rv)
+			}
+		}
+		return out, true
+	}
+	switch c := v.(type) {
+	case map[string]interface{}:
+		key := seg.Key
+		if normalize != nil {
+			key = normalize(key)
+		}
+		next, ok := c[key]
+		if !ok && normalize != nil {
+			// keys of a map that has not been normalized yet
+			next, ok = lookupNormalized(c, key, normalize)
+		}
+		if !ok {
+			return nil, false
+		}
+		return resolvePath(next, rest, normalize)
+	case []interface{}:
+		i, err := strconv.Atoi(seg.Key)
+		if err != nil {
+			return nil, false
+		}
+		if i < 0 {
+			i += len(c)
+		}
+		if i < 0 || i >= len(c) {
+			return nil, false
+		}
+		return resolvePath(c[i], rest, normalize)
+	}
+	return nil, false
+}
+
+// lookupNormalized finds the value whose normalized key equals key, preferring
+// the lexically smallest raw key when several normalize alike.
+func lookupNormalized(m map[string]interface{}, key string, normalize func(string) string) (interface{}, bool) {
+	found := ""
+	for k := range m {
+		if normalize(k) == key && (found == "" || k < found) {
+			found = k
+		}
+	}
+	if found == "" {
+		return nil, false
+	}
+	return m[found], true
+}
+
+// WithRoot decodes only the sub-document at path (same syntaxes as path tags,
+// e.g. "$.data.items" or "/data/items") instead of the whole input. The selected
+// value must be an object or an array of objects; a missing path fails with
+// ErrRootNotFound.
+//
+// Example:
+//
+//	var items []Item
+//	err := databridge.TransformToStructUniversal(resp, &items, databridge.WithRoot("$.data.items"))
+func WithRoot(path string) Option {
+	return func(c *config) { c.Root = path }
+}
+
+// selectRoot replaces the parsed intermediate with the sub-document at cfg.Root.
+func selectRoot(m map[string]interface{}, arr []map[string]interface{}, cfg *config) (map[string]interface{}, []map[string]interface{}, error) {
+	var doc interface{} = m
+	if arr != nil {
+		rows := make([]interface{}, len(arr))
+		for i, r := range arr {
+			rows[i] = r
+		}
+		doc = rows
+	}
+	var normalize func(string) string
+	if cfg.NormalizeKeys {
+		normalize = cfg.KeyNormalizer
+	}
+	v, ok := resolvePath(doc, parsePath(cfg.Root), normalize)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %q", ErrRootNotFound, cfg.Root)
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return x, nil, nil
+	case []interface{}:
+		rows := make([]map[string]interface{}, len(x))
+		for i, e := range x {
+			r, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf("databridge: root %q: element %d is %s, want an object", cfg.Root, i, describeGeneric(e))
+			}
+			rows[i] = r
+		}
+		return nil, rows, nil
+	}
+	return nil, nil, fmt.Errorf("databridge: root %q is %s, want an object or an array of objects", cfg.Root, describeGeneric(v))
+}
+
+// describeGeneric names the JSON kind of a generic value for error messages.
+func describeGeneric(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	}
+	return "a number"
+}
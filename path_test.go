@@ -0,0 +1,104 @@
+package databridge
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	cases := map[string][]pathSegment{
+		"data.attributes.name":   {{Key: "data"}, {Key: "attributes"}, {Key: "name"}},
+		"/data/a~1b/0":           {{Key: "data"}, {Key: "a/b"}, {Key: "0"}},
+		"$.items[*].name":        {{Key: "items"}, {Key: "*", Wildcard: true}, {Key: "name"}},
+		"$['odd.key'][2]":        {{Key: "odd.key"}, {Key: "2"}},
+		"items[0].tags.*":        {{Key: "items"}, {Key: "0"}, {Key: "tags"}, {Key: "*", Wildcard: true}},
+		"$.data.attributes.name": {{Key: "data"}, {Key: "attributes"}, {Key: "name"}},
+	}
+	for in, want := range cases {
+		if got := parsePath(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("parsePath(%q) = %+v, want %+v", in, got, want)
+		}
+	}
+}
+
+func TestPathTags(t *testing.T) {
+	type Flat struct {
+		ID       int      `json:"id" databridge:"path=data.id"`
+		Name     string   `json:"name" databridge:"path=/data/attributes/Full-Name"`
+		First    string   `json:"first" databridge:"path='$.data.items[0].sku'"`
+		Last     string   `json:"last" databridge:"path='$.data.items[-1].sku'"`
+		SKUs     []string `json:"skus" databridge:"path='$.data.items[*].sku'"`
+		Included int      `json:"included" databridge:"path=meta.count"`
+		Version  string   `json:"version"`
+	}
+	in := `{
+		"data": {"id": "7", "attributes": {"full_name": "Ada"},
+		         "items": [{"sku": "a1"}, {"qty": 2}, {"sku": "c3"}]},
+		"version": "v2"
+	}`
+	var got Flat
+	if err := TransformToStructUniversal(in, &got, WithStrict(true)); err != nil {
+		t.Fatalf("path transform failed: %v", err)
+	}
+	want := Flat{ID: 7, Name: "Ada", First: "a1", Last: "c3", SKUs: []string{"a1", "c3"}, Version: "v2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("path transform = %+v, want %+v", got, want)
+	}
+
+	// path tags bypass the fast path, so they work without key normalization too
+	got = Flat{}
+	if err := TransformToStructUniversal(`{"data":{"id":3,"attributes":{"Full-Name":"Bo"}}}`, &got, WithKeyNormalization(false)); err != nil {
+		t.Fatalf("path transform without normalization failed: %v", err)
+	}
+	if got.ID != 3 || got.Name != "Bo" {
+		t.Fatalf("path transform without normalization = %+v", got)
+	}
+}
+
+func TestWithRoot(t *testing.T) {
+	type Item struct {
+		SKU string `json:"sku"`
+		Qty int    `json:"qty"`
+	}
+	var items []Item
+	err := TransformToStructUniversal(`{"meta":{"page":1},"data":{"items":[{"SKU":"a","qty":"2"},{"sku":"b"}]}}`, &items, WithRoot("$.data.items"))
+	if err != nil {
+		t.Fatalf("root transform failed: %v", err)
+	}
+	if want := []Item{{SKU: "a", Qty: 2}, {SKU: "b"}}; !reflect.DeepEqual(items, want) {
+		t.Fatalf("root transform = %+v, want %+v", items, want)
+	}
+
+	// YAML and form inputs, object roots, pointer syntax
+	var it Item
+	if err := TransformToStructUniversal("data:\n  item:\n    sku: y\n    qty: 3\n", &it, WithYAML(true), WithRoot("/data/item")); err != nil || it != (Item{SKU: "y", Qty: 3}) {
+		t.Fatalf("yaml root = %+v err=%v", it, err)
+	}
+	it = Item{}
+	if err := TransformToStructUniversal("item.sku=fm&item.qty=4&other=1", &it, WithRoot("item"), WithStrict(true)); err != nil || it != (Item{SKU: "fm", Qty: 4}) {
+		t.Fatalf("form root = %+v err=%v", it, err)
+	}
+	// the fast path must not skip root selection
+	it = Item{}
+	if err := TransformToStructUniversal(`{"data":{"item":{"sku":"k"}}}`, &it, WithKeyNormalization(false), WithRoot("data.item")); err != nil || it.SKU != "k" {
+		t.Fatalf("root without normalization = %+v err=%v", it, err)
+	}
+}
+
+func TestWithRootErrors(t *testing.T) {
+	var items []map[string]interface{}
+	err := TransformToStructUniversal(`{"data":{}}`, &items, WithRoot("$.data.items"))
+	if !errors.Is(err, ErrRootNotFound) {
+		t.Fatalf("expected ErrRootNotFound, got %v", err)
+	}
+	err = TransformToStructUniversal(`{"data":{"items":[1,2]}}`, &items, WithRoot("$.data.items"))
+	if err == nil || !strings.Contains(err.Error(), "element 0 is a number") {
+		t.Fatalf("expected wrong shape error, got %v", err)
+	}
+	err = TransformToStructUniversal(`{"data":"x"}`, &items, WithRoot("data"))
+	if err == nil || !strings.Contains(err.Error(), "is a string, want an object or an array of objects") {
+		t.Fatalf("expected wrong shape error, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package databridge
+
+// Step reshapes one parsed record between parsing and binding. It returns the
+// (possibly new) record and whether to keep it. Records carry the input's own
+// keys, before key normalization, and values already converted by the parser.
+// Any func(map[string]interface{}) (map[string]interface{}, bool) can be used as
+// a Step; Rename, Drop, Compute and Filter cover the common cases.
+type Step func(row map[string]interface{}) (map[string]interface{}, bool)
+
+// WithPipeline runs steps in order on every record: the single object of JSON,
+// YAML, XML or form input, or each row of CSV and JSON array input. Rows a step
+// drops are left out of slice outputs; when a single object is dropped the
+// output is left unchanged.
+//
+// Example:
+//
+//	databridge.WithPipeline(
+//		databridge.Drop("ssn"),
+//		databridge.Rename("mail", "email"),
+//		databridge.Filter(func(r map[string]any) bool { return r["status"] != "deleted" }),
+//	)
+func WithPipeline(steps ...Step) Option {
+	return func(c *config) { c.Pipeline = append(c.Pipeline, steps...) }
+}
+
+// Rename moves the value at key from to key to, replacing any value already there.
+func Rename(from, to string) Step {
+	return func(row map[string]interface{}) (map[string]interface{}, bool) {
+		if v, ok := row[from]; ok {
+			delete(row, from)
+			row[to] = v
+		}
+		return row, true
+	}
+}
+
+// Drop removes keys from the record.
+func Drop(keys ...string) Step {
+	return func(row map[string]interface{}) (map[string]interface{}, bool) {
+		for _, k := range keys {
+			delete(row, k)
+		}
+		return row, true
+	}
+}
+
+// Compute sets key to the value fn derives from the record, e.g. a full name
+// from first and last name columns.
+func Compute(key string, fn func(row map[string]interface{}) interface{}) Step {
+	return func(row map[string]interface{}) (map[string]interface{}, bool) {
+		row[key] = fn(row)
+		return row, true
+	}
+}
+
+// Filter keeps only the records for which keep returns true.
+func Filter(keep func(row map[string]interface{}) bool) Step {
+	return func(row map[string]interface{}) (map[string]interface{}, bool) {
+		return row, keep(row)
+	}
+}
+
+// applyPipeline runs steps over the intermediate records. keep is false when a
+// single-object input was dropped.
+func applyPipeline(m map[string]interface{}, arr []map[string]interface{}, steps []Step) (map[string]interface{}, []map[string]interface{}, bool) {
+	if arr != nil {
+		rows := make([]map[string]interface{}, 0, len(arr))
+		for _, r := range arr {
+			if r, ok := runSteps(r, steps); ok {
+				rows = append(rows, r)
+			}
+		}
+		return nil, rows, true
+	}
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	m, ok := runSteps(m, steps)
+	return m, nil, ok
+}
+
+func runSteps(row map[string]interface{}, steps []Step) (map[string]interface{}, bool) {
+	for _, s := range steps {
+		var keep bool
+		if row, keep = s(row); !keep {
+			return nil, false
+		}
+		if row == nil {
+			row = map[string]interface{}{}
+		}
+	}
+	return row, true
+}
@@ -0,0 +1,99 @@
+package databridge
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type pipelinePerson struct {
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	Status   string `json:"status"`
+	SSN      string `json:"ssn"`
+}
+
+func testPipeline() Option {
+	return WithPipeline(
+		Drop("ssn"),
+		Rename("mail", "email"),
+		Compute("full_name", func(r map[string]interface{}) interface{} {
+			return fmt.Sprintf("%v %v", r["first"], r["last"])
+		}),
+		Drop("first", "last"),
+		Filter(func(r map[string]interface{}) bool { return r["status"] != "deleted" }),
+	)
+}
+
+func TestPipelineRows(t *testing.T) {
+	csv := "first,last,mail,status,ssn\nAda,Lovelace,ada@x.io,active,111\nBob,Smith,bob@x.io,deleted,222\nCy,Young,cy@x.io,active,333\n"
+	var got []pipelinePerson
+	if err := TransformToStructUniversal(csv, &got, testPipeline(), WithStrict(true)); err != nil {
+		t.Fatalf("pipeline csv failed: %v", err)
+	}
+	want := []pipelinePerson{
+		{FullName: "Ada Lovelace", Email: "ada@x.io", Status: "active"},
+		{FullName: "Cy Young", Email: "cy@x.io", Status: "active"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("pipeline csv = %+v, want %+v", got, want)
+	}
+
+	// JSON arrays behave identically
+	got = nil
+	js := `[{"first":"Ada","last":"Lovelace","mail":"ada@x.io","status":"active","ssn":"1"},{"first":"Bob","status":"deleted"}]`
+	if err := TransformToStructUniversal(js, &got, testPipeline()); err != nil {
+		t.Fatalf("pipeline json failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want[:1]) {
+		t.Fatalf("pipeline json = %+v, want %+v", got, want[:1])
+	}
+}
+
+func TestPipelineSingleObject(t *testing.T) {
+	var p pipelinePerson
+	if err := TransformToStructUniversal("first=Ada&last=L&mail=a@x.io&ssn=9", &p, testPipeline()); err != nil {
+		t.Fatalf("pipeline form failed: %v", err)
+	}
+	if p != (pipelinePerson{FullName: "Ada L", Email: "a@x.io"}) {
+		t.Fatalf("pipeline form = %+v", p)
+	}
+
+	// a dropped object leaves the output untouched
+	p = pipelinePerson{Email: "keep@x.io"}
+	if err := TransformToStructUniversal(`{"status":"deleted","mail":"new@x.io"}`, &p, testPipeline()); err != nil {
+		t.Fatalf("pipeline drop failed: %v", err)
+	}
+	if p.Email != "keep@x.io" {
+		t.Fatalf("dropped object changed output: %+v", p)
+	}
+
+	// hooks are plain funcs
+	hook := func(r map[string]interface{}) (map[string]interface{}, bool) {
+		return map[string]interface{}{"email": r["e"]}, true
+	}
+	if err := TransformToStructUniversal(`{"e":"hook@x.io"}`, &p, WithPipeline(hook), WithKeyNormalization(false)); err != nil || p.Email != "hook@x.io" {
+		t.Fatalf("pipeline hook = %+v err=%v", p, err)
+	}
+}
+
+type pipelineHooked struct {
+	Name  string `json:"name"`
+	Bound int    `json:"-"`
+}
+
+func (p *pipelineHooked) AfterBind() error {
+	p.Bound++
+	return nil
+}
+
+func TestPipelineDropSkipsAfterBind(t *testing.T) {
+	drop := WithPipeline(Filter(func(r map[string]interface{}) bool { return r["name"] != "skip" }))
+	var p pipelineHooked
+	if err := TransformToStructUniversal(`{"name":"skip"}`, &p, drop); err != nil || p.Bound != 0 {
+		t.Fatalf("AfterBind ran for a dropped object: %+v err=%v", p, err)
+	}
+	if err := TransformToStructUniversal(`{"name":"keep"}`, &p, drop); err != nil || p.Bound != 1 || p.Name != "keep" {
+		t.Fatalf("AfterBind not run for a kept object: %+v err=%v", p, err)
+	}
+}
@@ -0,0 +1,52 @@
+package databridge
+
+import "sort"
+
+// Presence is the set of target field paths that were supplied by the input,
+// including fields sent as explicit nulls. Paths use JSON names joined by dots
+// ("address.city"); rows of slice targets are prefixed with their index ("[0].name").
+type Presence map[string]struct{}
+
+// Has reports whether the field at path was supplied.
+func (p Presence) Has(path string) bool {
+	_, ok := p[path]
+	return ok
+}
+
+// Paths returns the supplied field paths in sorted order.
+func (p Presence) Paths() []string {
+	out := make([]string, 0, len(p))
+	for k := range p {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// add records paths under prefix. It is a no-op on a nil Presence.
+func (p Presence) add(prefix string, paths []string) {
+	if p == nil {
+		return
+	}
+	for _, path := range paths {
+		p[joinSubPath(prefix, path)] = struct{}{}
+	}
+}
+
+// TransformWithPresence decodes input into output like TransformToStructUniversal
+// and also returns which target fields the input supplied, so PATCH handlers can
+// tell an omitted field from one sent as a zero value or null.
+//
+// Example:
+//
+//	var patch UserPatch
+//	present, err := databridge.TransformWithPresence(r.Body, &patch)
+//	if present.Has("email") { /* update the email column */ }
+func TransformWithPresence(input interface{}, output interface{}, opts ...Option) (Presence, error) {
+	p := Presence{}
+	opts = append(opts, func(c *config) { c.presence = p })
+	if err := TransformToStructUniversal(input, output, opts...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
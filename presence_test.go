@@ -0,0 +1,57 @@
+package databridge
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestTransformWithPresence(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip"`
+	}
+	type Patch struct {
+		Name    string  `json:"name"`
+		Email   *string `json:"email"`
+		Age     int     `json:"age"`
+		Address Address `json:"address"`
+	}
+	var p Patch
+	present, err := TransformWithPresence(`{"Name":"","email":null,"address":{"City":"Paris"},"extra":1}`, &p)
+	if err != nil {
+		t.Fatalf("presence transform failed: %v", err)
+	}
+	want := []string{"address", "address.city", "email", "name"}
+	if got := present.Paths(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("presence = %v, want %v", got, want)
+	}
+	if present.Has("age") || present.Has("address.zip") || present.Has("extra") {
+		t.Fatalf("unexpected presence: %v", present.Paths())
+	}
+
+	// forms and the no-normalization fast path report presence too
+	present, err = TransformWithPresence(url.Values{"age": {"0"}}, &p)
+	if err != nil || !present.Has("age") || present.Has("name") {
+		t.Fatalf("form presence = %v err=%v", present.Paths(), err)
+	}
+	present, err = TransformWithPresence(`{"age":3}`, &p, WithKeyNormalization(false))
+	if err != nil || !present.Has("age") {
+		t.Fatalf("fast path presence = %v err=%v", present.Paths(), err)
+	}
+}
+
+func TestTransformWithPresenceRows(t *testing.T) {
+	type Row struct {
+		A string `json:"a"`
+		B string `json:"b"`
+	}
+	var rows []Row
+	present, err := TransformWithPresence(`[{"a":"1"},{"b":"2"}]`, &rows)
+	if err != nil {
+		t.Fatalf("rows presence failed: %v", err)
+	}
+	if want := []string{"[0].a", "[1].b"}; !reflect.DeepEqual(present.Paths(), want) {
+		t.Fatalf("rows presence = %v, want %v", present.Paths(), want)
+	}
+}
@@ -0,0 +1,93 @@
+package databridge
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the values of sensitive fields in errors and reports.
+const Redacted = "[REDACTED]"
+
+// WithRedactKeys marks fields whose JSON name matches one of keys (compared
+// after default key normalization, so "card_number" also covers "cardNumber")
+// as sensitive, like a `databridge:"sensitive"` tag. The values of sensitive
+// fields, and of everything nested in them, are masked wherever databridge
+// surfaces input data: FieldError and decode error messages, the Logger and
+// WithSlog events, observer and row errors, and TransformExplain reports.
+func WithRedactKeys(keys ...string) Option {
+	return func(c *config) {
+		if c.redactKeys == nil {
+			c.redactKeys = make(map[string]bool, len(keys))
+		}
+		for _, k := range keys {
+			c.redactKeys[defaultNormalizer(k)] = true
+		}
+	}
+}
+
+// sensitive reports whether the field named name with tag holds sensitive data.
+func (c *config) sensitive(name string, tag fieldTag) bool {
+	return tag.Sensitive || (c.redactKeys != nil && c.redactKeys[defaultNormalizer(name)])
+}
+
+// redactedError masks the message of an error about a sensitive value; the
+// original error stays in the chain for errors.Is and errors.As.
+type redactedError struct{ err error }
+
+func (e *redactedError) Error() string { return "invalid value " + Redacted }
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactFieldError masks the message of a *FieldError raised for a sensitive
+// field or one of its elements or nested fields.
+func redactFieldError(err error) error {
+	fe, ok := err.(*FieldError)
+	if !ok {
+		return err
+	}
+	if _, done := fe.Err.(*redactedError); done {
+		return err
+	}
+	return &FieldError{Path: fe.Path, Err: &redactedError{fe.Err}}
+}
+
+// redactDecodeError masks the value in a decode error for a sensitive field of
+// t; encoding/json reports numbers with their text, e.g. "number 4111111111".
+func (c *config) redactDecodeError(err error, t reflect.Type) error {
+	var te *json.UnmarshalTypeError
+	if !errors.As(err, &te) || !c.sensitivePath(t, te.Field) {
+		return err
+	}
+	r := *te
+	if kind, _, ok := strings.Cut(te.Value, " "); ok {
+		r.Value = kind + " " + Redacted
+	}
+	return &r
+}
+
+// sensitivePath reports whether the dotted JSON path, as found in decode errors,
+// leads through a sensitive field of t.
+func (c *config) sensitivePath(t reflect.Type, path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, seg := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		fi, ok := jsonFieldLookup(t)[seg]
+		if !ok {
+			return false
+		}
+		if c.sensitive(seg, fi.Tag) {
+			return true
+		}
+		t = fi.FieldType
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package databridge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactFieldError(t *testing.T) {
+	type Login struct {
+		User string `json:"user"`
+		PIN  int8   `json:"pin" databridge:"sensitive"`
+	}
+	var buf bytes.Buffer
+	var l Login
+	err := TransformToStructUniversal(`{"user":"ada","pin":"98765"}`, &l, WithSlog(slog.New(slog.NewJSONHandler(&buf, nil))))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "pin" {
+		t.Fatalf("err = %v", err)
+	}
+	if strings.Contains(err.Error(), "98765") || !strings.Contains(err.Error(), Redacted) {
+		t.Fatalf("value not redacted: %v", err)
+	}
+	if strings.Contains(buf.String(), "98765") {
+		t.Fatalf("value logged: %s", buf.String())
+	}
+	// the cause stays in the chain
+	if !strings.Contains(errors.Unwrap(fe.Err).Error(), "overflows") {
+		t.Fatalf("cause = %v", errors.Unwrap(fe.Err))
+	}
+}
+
+func TestRedactKeys(t *testing.T) {
+	type Card struct {
+		Number string `json:"number"`
+		Limits map[string]int8
+	}
+	type Payment struct {
+		Card   Card  `json:"card"`
+		Amount int64 `json:"amount"`
+	}
+	var logged []string
+	logger := WithLogger(func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	})
+
+	// nested values are covered, and keys match after normalization
+	var p Payment
+	err := TransformToStructUniversal(`{"card":{"number":"4111","Limits":{"daily":4111111111}},"amount":5}`, &p, WithRedactKeys("Card"), logger)
+	if err == nil || !errors.Is(err, ErrDecodeFailed) {
+		t.Fatalf("err = %v", err)
+	}
+	for _, s := range append(logged, err.Error()) {
+		if strings.Contains(s, "4111111111") {
+			t.Fatalf("value leaked: %s", s)
+		}
+	}
+	if !strings.Contains(err.Error(), "number "+Redacted) {
+		t.Fatalf("err = %v", err)
+	}
+
+	// other fields keep their values in errors
+	err = TransformToStructUniversal(`{"card":{"Limits":{"daily":300}}}`, &p, logger)
+	if err == nil || !strings.Contains(err.Error(), "number 300") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestRedactExplainAndRows(t *testing.T) {
+	type Account struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+		Count    uint8  `json:"count"`
+	}
+	var a Account
+	rep, err := TransformExplain(`{"name":{"first":"Ada"},"password":{"p":"hunter2"},"count":-3}`, &a, WithRedactKeys("password", "count"))
+	if err != nil {
+		t.Fatalf("TransformExplain: %v", err)
+	}
+	table := rep.Table()
+	if strings.Contains(table, "hunter2") || !strings.Contains(table, "Ada") {
+		t.Fatalf("table:\n%s", table)
+	}
+	masked := 0
+	for _, d := range rep.Dropped {
+		if d.Path == "name" {
+			continue
+		}
+		if d.Value != Redacted || !strings.HasSuffix(d.Reason, Redacted) {
+			t.Fatalf("dropped = %+v", d)
+		}
+		masked++
+	}
+	if masked != 2 {
+		t.Fatalf("dropped = %+v", rep.Dropped)
+	}
+
+	// CSV row errors reported to slog are masked too
+	type Row struct {
+		ID  int   `json:"id"`
+		PIN uint8 `json:"pin"`
+	}
+	var buf bytes.Buffer
+	seq := TransformSeqContext[Row](context.Background(), "id,pin\n1,4242\n", WithRedactKeys("pin"), WithSlog(slog.New(slog.NewJSONHandler(&buf, nil))))
+	for _, err := range seq {
+		if err == nil || strings.Contains(err.Error(), "4242") {
+			t.Fatalf("row err = %v", err)
+		}
+	}
+	if ev := findEvent(slogEvents(t, &buf), "record failed"); ev == nil || strings.Contains(fmt.Sprint(ev), "4242") {
+		t.Fatalf("record failed event = %v", ev)
+	}
+}
@@ -0,0 +1,464 @@
+package databridge
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SchemaDialect is the JSON Schema dialect of the schemas SchemaFor produces.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema (Draft 2020-12) document or subschema. It marshals to
+// and from the standard JSON form, omitting unset keywords; keywords it does not
+// model are ignored.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        SchemaType         `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Default     interface{}        `json:"default,omitempty"`
+	WriteOnly   bool               `json:"writeOnly,omitempty"`
+
+	Const json.RawMessage `json:"const,omitempty"`
+
+	// applicators
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+	If    *Schema   `json:"if,omitempty"`
+	Then  *Schema   `json:"then,omitempty"`
+	Else  *Schema   `json:"else,omitempty"`
+
+	// strings
+	MinLength       *int   `json:"minLength,omitempty"`
+	MaxLength       *int   `json:"maxLength,omitempty"`
+	Pattern         string `json:"pattern,omitempty"`
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+
+	// numbers
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
+
+	// arrays
+	PrefixItems []*Schema `json:"prefixItems,omitempty"`
+	Items       *Schema   `json:"items,omitempty"`
+	MinItems    *int      `json:"minItems,omitempty"`
+	MaxItems    *int      `json:"maxItems,omitempty"`
+	UniqueItems bool      `json:"uniqueItems,omitempty"`
+	Contains    *Schema   `json:"contains,omitempty"`
+	MinContains *int      `json:"minContains,omitempty"`
+	MaxContains *int      `json:"maxContains,omitempty"`
+
+	// objects
+	Properties           map[string]*Schema  `json:"properties,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	AdditionalProperties *Schema             `json:"additionalProperties,omitempty"`
+	MinProperties        *int                `json:"minProperties,omitempty"`
+	MaxProperties        *int                `json:"maxProperties,omitempty"`
+	PatternProperties    map[string]*Schema  `json:"patternProperties,omitempty"`
+	PropertyNames        *Schema             `json:"propertyNames,omitempty"`
+	DependentRequired    map[string][]string `json:"dependentRequired,omitempty"`
+	DependentSchemas     map[string]*Schema  `json:"dependentSchemas,omitempty"`
+
+	boolean *bool // set for the boolean schemas true and false
+	c       *compiledSchema
+}
+
+// schemaJSON has Schema's fields without its methods.
+type schemaJSON Schema
+
+// MarshalJSON encodes the schema, including the boolean schemas true and false.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.boolean != nil {
+		return json.Marshal(*s.boolean)
+	}
+	return json.Marshal((*schemaJSON)(s))
+}
+
+// UnmarshalJSON decodes a schema object or a boolean schema.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	var v bool
+	if err := json.Unmarshal(b, &v); err == nil {
+		*s = Schema{boolean: &v}
+		return nil
+	}
+	return json.Unmarshal(b, (*schemaJSON)(s))
+}
+
+// SchemaType is the "type" keyword: one type name, or several for values that
+// may take more than one JSON type.
+type SchemaType []string
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *SchemaType) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = SchemaType{one}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+// Has reports whether name is one of the types.
+func (t SchemaType) Has(name string) bool {
+	for _, n := range t {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaFor returns the JSON Schema of the input Transform[T] accepts: JSON
+// names as properties, Go kinds as types (integers bounded by their size),
+// time.Time as a date-time string, nested named structs in $defs and pointer
+// fields as nullable. ApplySchemaTags lists the struct tags that refine it.
+//
+// Example:
+//
+//	b, _ := json.MarshalIndent(databridge.SchemaFor[User](), "", "  ")
+func SchemaFor[T any]() *Schema {
+	return SchemaForType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// SchemaForType is SchemaFor for a reflect.Type.
+func SchemaForType(t reflect.Type) *Schema {
+	g := &schemaGen{root: derefType(t), defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+	s := g.schema(t)
+	s.Schema = SchemaDialect
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+// schemaGen builds the schema of one type, collecting named structs in defs.
+type schemaGen struct {
+	root  reflect.Type
+	defs  map[string]*Schema
+	names map[reflect.Type]string // struct type -> its $defs name
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+func (g *schemaGen) schema(t reflect.Type) *Schema {
+	t = derefType(t)
+	switch {
+	case t == timeType:
+		return &Schema{Type: SchemaType{"string"}, Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: SchemaType{"string", "number"}, Description: `Duration such as "1h30m", or seconds.`}
+	case hasGlobalConverter(t):
+		return &Schema{} // the converter decides what it accepts
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return &Schema{Type: SchemaType{"string"}}
+	case reflect.PointerTo(t).Implements(jsonUnmarshalerType):
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := &Schema{Type: SchemaType{"integer"}}
+		if t.Bits() < 64 {
+			s.Minimum = floatPtr(-math.Exp2(float64(t.Bits() - 1)))
+			s.Maximum = floatPtr(math.Exp2(float64(t.Bits()-1)) - 1)
+		}
+		return s
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s := &Schema{Type: SchemaType{"integer"}, Minimum: floatPtr(0)}
+		if t.Bits() < 64 {
+			s.Maximum = floatPtr(math.Exp2(float64(t.Bits())) - 1)
+		}
+		return s
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaType{"string"}, ContentEncoding: "base64"}
+		}
+		s := &Schema{Type: SchemaType{"array"}, Items: g.schema(t.Elem())}
+		if t.Kind() == reflect.Array {
+			s.MinItems, s.MaxItems = intPtr(t.Len()), intPtr(t.Len())
+		}
+		return s
+	case reflect.Map:
+		return &Schema{Type: SchemaType{"object"}, AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	}
+	return &Schema{} // interfaces accept anything
+}
+
+// structRef returns a reference to the schema of a named struct, building it in
+// $defs on first use; the root and anonymous structs are inlined.
+func (g *schemaGen) structRef(t reflect.Type) *Schema {
+	if t == g.root {
+		if _, building := g.names[t]; building {
+			return &Schema{Ref: "#"}
+		}
+		g.names[t] = ""
+		return g.object(t)
+	}
+	if t.Name() == "" {
+		return g.object(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = g.defName(t)
+		g.names[t] = name
+		g.defs[name] = &Schema{} // placeholder for recursive references
+		*g.defs[name] = *g.object(t)
+	}
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+var defNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// defName names t in $defs: its bare name, qualified by package when another
+// type already took it.
+func (g *schemaGen) defName(t reflect.Type) string {
+	name := defNameUnsafe.ReplaceAllString(t.Name(), "_")
+	if _, taken := g.defs[name]; taken {
+		name = defNameUnsafe.ReplaceAllString(t.String(), "_")
+	}
+	return name
+}
+
+func (g *schemaGen) object(t reflect.Type) *Schema {
+	s := &Schema{Type: SchemaType{"object"}, Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := jsonFieldName(f)
+		if !ok {
+			continue
+		}
+		fs := g.schema(f.Type)
+		if ApplySchemaTags(fs, f.Name, f.Tag) {
+			s.Required = append(s.Required, name)
+		}
+		if f.Type.Kind() == reflect.Ptr {
+			fs = nullable(fs)
+		}
+		s.Properties[name] = fs
+	}
+	return s
+}
+
+// nullable makes s also accept null: "null" joins its types, and a reference is
+// wrapped in anyOf with a null schema. Schemas without a type already accept it.
+func nullable(s *Schema) *Schema {
+	switch {
+	case len(s.Type) > 0:
+		s.Type = append(s.Type, "null")
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: SchemaType{"null"}}}}
+	}
+	return s
+}
+
+// WriteSchemaFile writes s, indented, to dir/<name>.schema.json. databridge-gen
+// -schema uses it.
+func WriteSchemaFile(dir, name string, s *Schema) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+".schema.json"), append(b, '\n'), 0644)
+}
+
+// ApplySchemaTags refines the schema s of a struct field from its tags and
+// reports whether the field is required. SchemaFor uses it; it understands
+//
+//   - validate (or binding) rules: required, min, max, len, gt, gte, lt, lte
+//     (lengths for strings, arrays and objects, bounds for numbers), oneof
+//     (as enum), email, url, uri, uuid, ipv4, ipv6, hostname, datetime=layout,
+//     and dive, which applies the rules after it to array items
+//   - default: the value of the default keyword, read as the field's type
+//   - databridge: layout (date, time or a description), str max length,
+//     sensitive (writeOnly) and conv (any value, for the converter to decide)
+//
+// A Go field name that differs from the JSON name is described as an alias, as
+// the mapping accepts both.
+func ApplySchemaTags(s *Schema, fieldName string, tag reflect.StructTag) (required bool) {
+	ft := parseFieldTag(tag.Get("databridge"))
+	if ft.Conv != "" {
+		*s = Schema{}
+	}
+	jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+	if jsonName != "" && jsonName != "-" && defaultNormalizer(jsonName) != defaultNormalizer(fieldName) {
+		appendDescription(s, fmt.Sprintf("Also accepted as %q.", fieldName))
+	}
+	if ft.Path != "" {
+		appendDescription(s, fmt.Sprintf("Read from %s.", ft.Path))
+	}
+	if ft.Layout != "" && s.Format == "date-time" {
+		applyTimeLayout(s, ft.Layout)
+	}
+	if ft.Str != nil && ft.Str.MaxLength > 0 && !ft.Str.Truncate && s.Type.Has("string") {
+		s.MaxLength = intPtr(ft.Str.MaxLength)
+	}
+	if ft.Sensitive {
+		s.WriteOnly = true
+	}
+	rules, ok := tag.Lookup("validate")
+	if !ok {
+		rules = tag.Get("binding")
+	}
+	target := s
+	for _, rule := range strings.Split(rules, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "required":
+			if target == s {
+				required = true
+			}
+		case "dive":
+			switch {
+			case target.Items != nil:
+				target = target.Items
+			case target.AdditionalProperties != nil:
+				target = target.AdditionalProperties
+			default:
+				target = &Schema{} // nothing to dive into: ignore the rules after it
+			}
+		case "min", "gte":
+			setBound(target, val, &target.Minimum, &target.MinLength, &target.MinItems, &target.MinProperties)
+		case "max", "lte":
+			setBound(target, val, &target.Maximum, &target.MaxLength, &target.MaxItems, &target.MaxProperties)
+		case "gt":
+			setBound(target, val, &target.ExclusiveMinimum, nil, nil, nil)
+		case "lt":
+			setBound(target, val, &target.ExclusiveMaximum, nil, nil, nil)
+		case "len":
+			setBound(target, val, nil, &target.MinLength, &target.MinItems, &target.MinProperties)
+			setBound(target, val, nil, &target.MaxLength, &target.MaxItems, &target.MaxProperties)
+		case "oneof":
+			target.Enum = nil
+			for _, v := range strings.Fields(val) {
+				target.Enum = append(target.Enum, schemaValue(target, v))
+			}
+		case "email", "uuid", "ipv4", "ipv6", "hostname":
+			target.Format = key
+		case "url", "uri":
+			target.Format = "uri"
+		case "datetime":
+			target.Format = "date-time"
+			applyTimeLayout(target, val)
+		}
+	}
+	if d, ok := tag.Lookup("default"); ok {
+		s.Default = schemaValue(s, d)
+	}
+	return required
+}
+
+// applyTimeLayout sets the format of a time string parsed with layout.
+func applyTimeLayout(s *Schema, layout string) {
+	switch layout {
+	case "2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05.999999999Z07:00":
+		s.Format = "date-time"
+	case "2006-01-02":
+		s.Format = "date"
+	case "15:04:05", "15:04:05Z07:00":
+		s.Format = "time"
+	default:
+		s.Format = ""
+		appendDescription(s, fmt.Sprintf("Time in the layout %q.", layout))
+	}
+}
+
+// setBound sets the bound val of a rule on the keyword matching s's type: num for
+// numbers, str for string lengths, arr for array lengths and obj for objects. A
+// nil keyword means the rule does not apply to that type.
+func setBound(s *Schema, val string, num **float64, str, arr, obj **int) {
+	switch {
+	case s.Type.Has("string"):
+		if n, err := strconv.Atoi(val); err == nil && str != nil {
+			*str = intPtr(n)
+		}
+	case s.Type.Has("array"):
+		if n, err := strconv.Atoi(val); err == nil && arr != nil {
+			*arr = intPtr(n)
+		}
+	case s.Type.Has("object"):
+		if n, err := strconv.Atoi(val); err == nil && obj != nil {
+			*obj = intPtr(n)
+		}
+	case s.Type.Has("integer") || s.Type.Has("number"):
+		if f, err := strconv.ParseFloat(val, 64); err == nil && num != nil {
+			*num = floatPtr(f)
+		}
+	}
+}
+
+// schemaValue reads the tag value v as an instance of s's type, falling back to
+// the string.
+func schemaValue(s *Schema, v string) interface{} {
+	switch {
+	case s.Type.Has("integer"):
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case s.Type.Has("number"):
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case s.Type.Has("boolean"):
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+func appendDescription(s *Schema, text string) {
+	if s.Description != "" {
+		s.Description += " "
+	}
+	s.Description += text
+}
+
+func hasGlobalConverter(t reflect.Type) bool {
+	globalConvertersMu.RLock()
+	defer globalConvertersMu.RUnlock()
+	_, ok := globalConverters.byType[t]
+	return ok
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func intPtr(n int) *int           { return &n }
+func floatPtr(f float64) *float64 { return &f }
@@ -0,0 +1,132 @@
+package databridge
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaAddress struct {
+	City string `json:"city" validate:"required,min=2"`
+}
+
+type schemaNode struct {
+	Name string       `json:"name"`
+	Kids []schemaNode `json:"kids"`
+}
+
+type schemaUser struct {
+	ID       int8           `json:"id" validate:"required"`
+	Name     string         `json:"display" databridge:"str=max=40"`
+	Email    *string        `json:"email" validate:"omitempty,email"`
+	Born     time.Time      `json:"born" databridge:"layout=2006-01-02"`
+	Seen     time.Time      `json:"seen"`
+	Tags     []string       `json:"tags" validate:"max=5,dive,oneof=a b"`
+	Home     schemaAddress  `json:"home"`
+	Work     *schemaAddress `json:"work"`
+	Tree     schemaNode     `json:"tree"`
+	Password string         `json:"password" databridge:"sensitive"`
+	Level    int            `json:"level" default:"3" binding:"gte=1,lte=9"`
+	Score    uint16         `json:"score"`
+	Raw      []byte         `json:"raw"`
+	Self     *schemaUser    `json:"self"`
+	skipped  string
+}
+
+func TestSchemaFor(t *testing.T) {
+	s := SchemaFor[schemaUser]()
+	if s.Schema != SchemaDialect || !s.Type.Has("object") {
+		t.Fatalf("root = %+v", s)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"id"}) {
+		t.Fatalf("required = %v", s.Required)
+	}
+	p := s.Properties
+	if len(p) != 14 {
+		t.Fatalf("properties = %v", p)
+	}
+	if id := p["id"]; *id.Minimum != -128 || *id.Maximum != 127 {
+		t.Fatalf("id = %+v", id)
+	}
+	if d := p["display"]; *d.MaxLength != 40 || !strings.Contains(d.Description, `"Name"`) {
+		t.Fatalf("display = %+v", d)
+	}
+	if e := p["email"]; !reflect.DeepEqual(e.Type, SchemaType{"string", "null"}) || e.Format != "email" {
+		t.Fatalf("email = %+v", e)
+	}
+	if p["born"].Format != "date" || p["seen"].Format != "date-time" {
+		t.Fatalf("born = %+v, seen = %+v", p["born"], p["seen"])
+	}
+	if tags := p["tags"]; *tags.MaxItems != 5 || !reflect.DeepEqual(tags.Items.Enum, []interface{}{"a", "b"}) {
+		t.Fatalf("tags = %+v", tags)
+	}
+	if p["home"].Ref != "#/$defs/schemaAddress" {
+		t.Fatalf("home = %+v", p["home"])
+	}
+	// pointers to named structs are nullable references
+	for name, ref := range map[string]string{"work": "#/$defs/schemaAddress", "self": "#"} {
+		if a := p[name].AnyOf; len(a) != 2 || a[0].Ref != ref || !reflect.DeepEqual(a[1].Type, SchemaType{"null"}) {
+			t.Fatalf("%s = %+v", name, p[name])
+		}
+	}
+	if kids := s.Defs["schemaNode"].Properties["kids"]; kids.Items.Ref != "#/$defs/schemaNode" {
+		t.Fatalf("kids = %+v", kids)
+	}
+	if a := s.Defs["schemaAddress"]; *a.Properties["city"].MinLength != 2 || a.Required[0] != "city" {
+		t.Fatalf("address = %+v", a)
+	}
+	if !p["password"].WriteOnly {
+		t.Fatalf("password = %+v", p["password"])
+	}
+	if l := p["level"]; l.Default != int64(3) || *l.Minimum != 1 || *l.Maximum != 9 {
+		t.Fatalf("level = %+v", l)
+	}
+	if sc := p["score"]; *sc.Minimum != 0 || *sc.Maximum != 65535 {
+		t.Fatalf("score = %+v", sc)
+	}
+	if p["raw"].ContentEncoding != "base64" {
+		t.Fatalf("raw = %+v", p["raw"])
+	}
+
+	// the JSON form uses the standard keywords and round-trips
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"$schema":"https://json-schema.org/draft/2020-12/schema"`, `"type":["string","null"]`, `"$ref":"#/$defs/schemaAddress"`} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("missing %s in %s", want, b)
+		}
+	}
+	var back Schema
+	if err := json.Unmarshal(b, &back); err != nil || !reflect.DeepEqual(back.Properties["email"].Type, SchemaType{"string", "null"}) {
+		t.Fatalf("round trip: %v %+v", err, back.Properties["email"])
+	}
+}
+
+func TestSchemaForSlice(t *testing.T) {
+	s := SchemaFor[[]schemaAddress]()
+	if !s.Type.Has("array") || s.Items.Ref != "#/$defs/schemaAddress" || s.Defs["schemaAddress"] == nil {
+		t.Fatalf("schema = %+v", s)
+	}
+}
+
+func TestSchemaForValidatesNullPointers(t *testing.T) {
+	type Order struct {
+		ID   int            `json:"id"`
+		Work *schemaAddress `json:"work"`
+	}
+	b, err := json.Marshal(SchemaFor[Order]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := Transform[Order](`{"id":1,"work":null}`, WithSchema(b))
+	if err != nil || o.Work != nil {
+		t.Fatalf("null pointer rejected: %+v err=%v", o, err)
+	}
+	if _, err := Transform[Order](`{"id":1,"work":{"city":"X"}}`, WithSchema(b)); err == nil {
+		t.Fatal("expected the referenced schema to still apply")
+	}
+}
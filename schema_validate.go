@@ -0,0 +1,685 @@
+package databridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrSchemaViolation = errors.New("databridge: input does not match schema")
+
+// SchemaViolation is one failed check of WithSchema. Path is the JSON Pointer
+// (RFC 6901) of the offending value in the input, "" for the whole document;
+// Keyword is the schema keyword that failed. Messages describe the expectation
+// and never contain input values, so they are safe to log.
+type SchemaViolation struct {
+	Path    string
+	Keyword string
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + v.Message
+}
+
+// SchemaError is returned when input fails WithSchema validation. It wraps
+// ErrSchemaViolation.
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+// maxReportedViolations bounds the violations listed by SchemaError.Error.
+const maxReportedViolations = 10
+
+func (e *SchemaError) Error() string {
+	n := len(e.Violations)
+	if n > maxReportedViolations {
+		n = maxReportedViolations
+	}
+	parts := make([]string, n)
+	for i, v := range e.Violations[:n] {
+		parts[i] = v.String()
+	}
+	msg := fmt.Sprintf("%v: %s", ErrSchemaViolation, strings.Join(parts, "; "))
+	if extra := len(e.Violations) - n; extra > 0 {
+		msg += fmt.Sprintf(" (and %d more)", extra)
+	}
+	return msg
+}
+
+func (e *SchemaError) Unwrap() error { return ErrSchemaViolation }
+
+// WithSchema validates input against a JSON Schema (Draft 2020-12) after it is
+// parsed, whatever its format, and before WithRoot, WithPipeline and mapping.
+// CSV and form values are read as numbers or booleans when they look like one,
+// and as the text they were sent as where the schema wants a string. A
+// schema of type "array" validates the list of records of CSV or JSON array
+// input; any other schema validates each record, with paths starting at its
+// index ("/3/email"). Violations fail the transform with a *SchemaError.
+//
+// $ref is resolved within the schema ("#", "#/$defs/name" or any JSON Pointer);
+// the formats date-time, date, time, email, uuid, ipv4, ipv6, uri and hostname
+// are asserted. An invalid schema makes every transform using the option fail.
+//
+// Example:
+//
+//	b := databridge.NewBridge(databridge.WithSchema(partnerSchema))
+func WithSchema(schema []byte) Option {
+	s, err := compileSchema(schema)
+	return func(c *config) { c.schema, c.schemaErr = s, err }
+}
+
+// validateInput checks the parsed input against the WithSchema schema.
+func (c *config) validateInput(m map[string]interface{}, rows []map[string]interface{}) error {
+	if c.schemaErr != nil {
+		return c.schemaErr
+	}
+	var vs []SchemaViolation
+	switch {
+	case rows == nil:
+		vs = c.schema.validate(withText(m, c.textMap), "", vs)
+	case c.schema.Type.Has("array"):
+		list := make([]interface{}, len(rows))
+		for i, r := range rows {
+			list[i] = withText(r, c.textRow(i))
+		}
+		vs = c.schema.validate(list, "", vs)
+	default:
+		for i, r := range rows {
+			vs = c.schema.validate(withText(r, c.textRow(i)), "/"+strconv.Itoa(i), vs)
+		}
+	}
+	if len(vs) > 0 {
+		return &SchemaError{Violations: vs}
+	}
+	return nil
+}
+
+// keepFormText keeps the text of form values for validation.
+func (c *config) keepFormText(vals url.Values) {
+	if c.schema != nil {
+		c.textMap = formValuesToMapWithDots(vals, &config{})
+	}
+}
+
+func (c *config) textRow(i int) map[string]interface{} {
+	if i < len(c.textRows) {
+		return c.textRows[i]
+	}
+	return nil
+}
+
+// textValue is a form or CSV value seen by validation both as the text it was
+// read from and as the value type detection made of it ("01234" and 1234).
+type textValue struct {
+	text  string
+	typed interface{}
+}
+
+// as returns the reading of t that satisfies types: the detected value when
+// its type is allowed or no type is given, else the text when strings are.
+func (t textValue) as(types SchemaType) interface{} {
+	if k := jsonKind(t.typed); len(types) == 0 || types.Has(k) || (k == "integer" && types.Has("number")) {
+		return t.typed
+	}
+	if types.Has("string") {
+		return t.text
+	}
+	return t.typed
+}
+
+// withText pairs the parsed value v with its text: detected values whose text
+// differs become textValues. v is returned unchanged when there is no text.
+func withText(v, text interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		tm, ok := text.(map[string]interface{})
+		if !ok {
+			return x
+		}
+		out := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			out[k] = withText(e, tm[k])
+		}
+		return out
+	case []interface{}:
+		ts, ok := text.([]interface{})
+		if !ok || len(ts) != len(x) {
+			return x
+		}
+		out := make([]interface{}, len(x))
+		for i, e := range x {
+			out[i] = withText(e, ts[i])
+		}
+		return out
+	}
+	if s, ok := text.(string); ok && v != interface{}(s) {
+		return textValue{text: s, typed: v}
+	}
+	return v
+}
+
+// compiledSchema holds what validation derives from a Schema once.
+type compiledSchema struct {
+	ref          *Schema
+	constVal     interface{}
+	hasConst     bool
+	pattern      *regexp.Regexp
+	patternProps []patternSchema
+}
+
+type patternSchema struct {
+	re *regexp.Regexp
+	s  *Schema
+}
+
+// compileSchema parses a schema document and resolves its references.
+func compileSchema(b []byte) (*Schema, error) {
+	var root Schema
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("databridge: invalid schema: %w", err)
+	}
+	var doc interface{}
+	_ = json.Unmarshal(b, &doc)
+	sc := &schemaCompiler{doc: doc, root: &root, refs: map[string]*Schema{"#": &root}}
+	if err := sc.compile(&root); err != nil {
+		return nil, fmt.Errorf("databridge: invalid schema: %w", err)
+	}
+	return &root, nil
+}
+
+type schemaCompiler struct {
+	doc  interface{} // the schema as generic JSON, for resolving pointers
+	root *Schema
+	refs map[string]*Schema
+}
+
+func (sc *schemaCompiler) compile(s *Schema) error {
+	if s == nil || s.c != nil || s.boolean != nil {
+		return nil
+	}
+	s.c = &compiledSchema{}
+	var err error
+	if s.Ref != "" {
+		if s.c.ref, err = sc.resolve(s.Ref); err != nil {
+			return err
+		}
+	}
+	if s.Const != nil {
+		s.c.hasConst = true
+		if err := json.Unmarshal(s.Const, &s.c.constVal); err != nil {
+			return fmt.Errorf("const: %w", err)
+		}
+	}
+	if s.Pattern != "" {
+		if s.c.pattern, err = regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("pattern: %w", err)
+		}
+	}
+	for _, p := range sortedKeys(s.PatternProperties) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("patternProperties: %w", err)
+		}
+		s.c.patternProps = append(s.c.patternProps, patternSchema{re, s.PatternProperties[p]})
+	}
+	subs := []*Schema{s.Not, s.If, s.Then, s.Else, s.Items, s.Contains, s.AdditionalProperties, s.PropertyNames}
+	subs = append(subs, s.AllOf...)
+	subs = append(subs, s.AnyOf...)
+	subs = append(subs, s.OneOf...)
+	subs = append(subs, s.PrefixItems...)
+	for _, m := range []map[string]*Schema{s.Defs, s.Properties, s.PatternProperties, s.DependentSchemas} {
+		for _, sub := range m {
+			subs = append(subs, sub)
+		}
+	}
+	for _, sub := range subs {
+		if err := sc.compile(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema ref points to within the document.
+func (sc *schemaCompiler) resolve(ref string) (*Schema, error) {
+	if s, ok := sc.refs[ref]; ok {
+		return s, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q: only references within the schema are resolved", ref)
+	}
+	frag, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("$ref %q: %w", ref, err)
+	}
+	v := sc.doc
+	for _, tok := range strings.Split(frag[1:], "/") {
+		tok = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+		switch x := v.(type) {
+		case map[string]interface{}:
+			v = x[tok]
+		case []interface{}:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+			v = x[i]
+		default:
+			v = nil
+		}
+		if v == nil {
+			return nil, fmt.Errorf("$ref %q not found", ref)
+		}
+	}
+	b, _ := json.Marshal(v)
+	s := &Schema{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("$ref %q: %w", ref, err)
+	}
+	sc.refs[ref] = s // before compiling, for recursive schemas
+	return s, sc.compile(s)
+}
+
+// validate appends the violations of v, found at path, to vs.
+func (s *Schema) validate(v interface{}, path string, vs []SchemaViolation) []SchemaViolation {
+	if s == nil {
+		return vs
+	}
+	if s.boolean != nil {
+		if !*s.boolean {
+			vs = append(vs, SchemaViolation{path, "false", "no value is allowed here"})
+		}
+		return vs
+	}
+	fail := func(keyword, format string, args ...interface{}) {
+		vs = append(vs, SchemaViolation{path, keyword, fmt.Sprintf(format, args...)})
+	}
+	if s.c != nil && s.c.ref != nil {
+		vs = s.c.ref.validate(v, path, vs)
+	}
+	// the combinators below get the textValue itself, to read it their own way
+	orig := v
+	tv, isText := v.(textValue)
+	if isText {
+		v = tv.as(s.Type)
+	}
+	equal := func(x interface{}) bool { return jsonEqual(v, x) || (isText && jsonEqual(tv.text, x)) }
+	kind := jsonKind(v)
+	if len(s.Type) > 0 && !s.Type.Has(kind) && !(kind == "integer" && s.Type.Has("number")) {
+		fail("type", "got %s, want %s", kind, strings.Join(s.Type, " or "))
+		return vs // the other keywords would only repeat the mismatch
+	}
+	if s.c != nil && s.c.hasConst && !equal(s.c.constVal) {
+		fail("const", "must be %s", formatValue(s.c.constVal))
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if equal(e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			opts := make([]string, len(s.Enum))
+			for i, e := range s.Enum {
+				opts[i] = formatValue(e)
+			}
+			fail("enum", "must be one of %s", strings.Join(opts, ", "))
+		}
+	}
+	switch x := v.(type) {
+	case string:
+		vs = s.validateString(x, path, vs)
+	case map[string]interface{}:
+		vs = s.validateObject(x, path, vs)
+	case []interface{}:
+		vs = s.validateArray(x, path, vs)
+	default:
+		if f, ok := schemaNumber(v); ok {
+			vs = s.validateNumber(f, path, vs)
+		}
+		// without a type, string keywords still apply to the text
+		if isText && len(s.Type) == 0 {
+			vs = s.validateString(tv.text, path, vs)
+		}
+	}
+	for _, sub := range s.AllOf {
+		vs = sub.validate(orig, path, vs)
+	}
+	if len(s.AnyOf) > 0 && s.matches(s.AnyOf, orig, path) == 0 {
+		fail("anyOf", "must match at least one of %d schemas", len(s.AnyOf))
+	}
+	if len(s.OneOf) > 0 {
+		if n := s.matches(s.OneOf, orig, path); n != 1 {
+			fail("oneOf", "must match exactly one of %d schemas, matched %d", len(s.OneOf), n)
+		}
+	}
+	if s.Not != nil && len(s.Not.validate(orig, path, nil)) == 0 {
+		fail("not", "must not match the schema")
+	}
+	if s.If != nil {
+		if len(s.If.validate(orig, path, nil)) == 0 {
+			vs = s.Then.validate(orig, path, vs)
+		} else {
+			vs = s.Else.validate(orig, path, vs)
+		}
+	}
+	return vs
+}
+
+// matches counts the schemas v is valid against.
+func (s *Schema) matches(schemas []*Schema, v interface{}, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(sub.validate(v, path, nil)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Schema) validateString(x string, path string, vs []SchemaViolation) []SchemaViolation {
+	fail := func(keyword, format string, args ...interface{}) {
+		vs = append(vs, SchemaViolation{path, keyword, fmt.Sprintf(format, args...)})
+	}
+	n := utf8.RuneCountInString(x)
+	if s.MinLength != nil && n < *s.MinLength {
+		fail("minLength", "must be at least %d characters", *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		fail("maxLength", "must be at most %d characters", *s.MaxLength)
+	}
+	if s.c != nil && s.c.pattern != nil && !s.c.pattern.MatchString(x) {
+		fail("pattern", "must match %q", s.Pattern)
+	}
+	if s.Format != "" && !validFormat(s.Format, x) {
+		fail("format", "must be a valid %s", s.Format)
+	}
+	return vs
+}
+
+func (s *Schema) validateNumber(f float64, path string, vs []SchemaViolation) []SchemaViolation {
+	fail := func(keyword, format string, args ...interface{}) {
+		vs = append(vs, SchemaViolation{path, keyword, fmt.Sprintf(format, args...)})
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		fail("minimum", "must be >= %v", *s.Minimum)
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		fail("maximum", "must be <= %v", *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum {
+		fail("exclusiveMinimum", "must be > %v", *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum {
+		fail("exclusiveMaximum", "must be < %v", *s.ExclusiveMaximum)
+	}
+	if m := s.MultipleOf; m != nil && *m > 0 {
+		q := f / *m
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("multipleOf", "must be a multiple of %v", *m)
+		}
+	}
+	return vs
+}
+
+func (s *Schema) validateObject(x map[string]interface{}, path string, vs []SchemaViolation) []SchemaViolation {
+	fail := func(at, keyword, format string, args ...interface{}) {
+		vs = append(vs, SchemaViolation{at, keyword, fmt.Sprintf(format, args...)})
+	}
+	if s.MinProperties != nil && len(x) < *s.MinProperties {
+		fail(path, "minProperties", "must have at least %d properties", *s.MinProperties)
+	}
+	if s.MaxProperties != nil && len(x) > *s.MaxProperties {
+		fail(path, "maxProperties", "must have at most %d properties", *s.MaxProperties)
+	}
+	for _, r := range s.Required {
+		if _, ok := x[r]; !ok {
+			fail(pointerJoin(path, r), "required", "is required")
+		}
+	}
+	for _, k := range sortedKeys(s.DependentRequired) {
+		if _, ok := x[k]; !ok {
+			continue
+		}
+		for _, r := range s.DependentRequired[k] {
+			if _, ok := x[r]; !ok {
+				fail(pointerJoin(path, r), "dependentRequired", "is required when %q is present", k)
+			}
+		}
+	}
+	for _, k := range sortedKeys(s.DependentSchemas) {
+		if _, ok := x[k]; ok {
+			vs = s.DependentSchemas[k].validate(x, path, vs)
+		}
+	}
+	for _, k := range sortedKeys(x) {
+		at := pointerJoin(path, k)
+		if s.PropertyNames != nil {
+			vs = s.PropertyNames.validate(k, at, vs)
+		}
+		matched := false
+		if sub, ok := s.Properties[k]; ok {
+			matched = true
+			vs = sub.validate(x[k], at, vs)
+		}
+		if s.c != nil {
+			for _, pp := range s.c.patternProps {
+				if pp.re.MatchString(k) {
+					matched = true
+					vs = pp.s.validate(x[k], at, vs)
+				}
+			}
+		}
+		if !matched && s.AdditionalProperties != nil {
+			if ap := s.AdditionalProperties; ap.boolean != nil && !*ap.boolean {
+				fail(at, "additionalProperties", "is not allowed")
+			} else {
+				vs = ap.validate(x[k], at, vs)
+			}
+		}
+	}
+	return vs
+}
+
+func (s *Schema) validateArray(x []interface{}, path string, vs []SchemaViolation) []SchemaViolation {
+	fail := func(keyword, format string, args ...interface{}) {
+		vs = append(vs, SchemaViolation{path, keyword, fmt.Sprintf(format, args...)})
+	}
+	if s.MinItems != nil && len(x) < *s.MinItems {
+		fail("minItems", "must have at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(x) > *s.MaxItems {
+		fail("maxItems", "must have at most %d items", *s.MaxItems)
+	}
+	for i, e := range x {
+		at := pointerJoin(path, strconv.Itoa(i))
+		if i < len(s.PrefixItems) {
+			vs = s.PrefixItems[i].validate(e, at, vs)
+		} else {
+			vs = s.Items.validate(e, at, vs)
+		}
+	}
+	if s.UniqueItems {
+	unique:
+		for i := range x {
+			for j := i + 1; j < len(x); j++ {
+				if jsonEqual(x[i], x[j]) {
+					fail("uniqueItems", "items %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+	if s.Contains != nil {
+		n := 0
+		for i, e := range x {
+			if len(s.Contains.validate(e, pointerJoin(path, strconv.Itoa(i)), nil)) == 0 {
+				n++
+			}
+		}
+		min := 1
+		if s.MinContains != nil {
+			min = *s.MinContains
+		}
+		if n < min {
+			fail("contains", "must contain at least %d matching items", min)
+		}
+		if s.MaxContains != nil && n > *s.MaxContains {
+			fail("maxContains", "must contain at most %d matching items", *s.MaxContains)
+		}
+	}
+	return vs
+}
+
+// jsonKind returns the JSON Schema type of a parsed value; integral numbers are
+// "integer".
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	if f, ok := schemaNumber(v); ok {
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// schemaNumber reads the numeric types parsers produce.
+func schemaNumber(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case float64:
+		return x, true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonEqual compares parsed values as JSON, numbers by value.
+func jsonEqual(a, b interface{}) bool {
+	if t, ok := a.(textValue); ok {
+		a = t.typed
+	}
+	if t, ok := b.(textValue); ok {
+		b = t.typed
+	}
+	if fa, ok := schemaNumber(a); ok {
+		fb, ok := schemaNumber(b)
+		return ok && fa == fb
+	}
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+)
+
+// validFormat checks the asserted formats; others are annotations and pass.
+func validFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", s)
+		if err != nil {
+			_, err = time.Parse("15:04:05.999999999Z07:00", s)
+		}
+		return err == nil
+	case "email":
+		a, err := mail.ParseAddress(s)
+		return err == nil && a.Address == s
+	case "uuid":
+		return uuidPattern.MatchString(s)
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	case "ipv6":
+		ip := net.ParseIP(s)
+		return ip != nil && strings.Contains(s, ":")
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	case "hostname":
+		return len(s) <= 253 && hostnamePattern.MatchString(s)
+	}
+	return true
+}
+
+// pointerJoin appends an escaped reference token to a JSON Pointer.
+func pointerJoin(path, tok string) string {
+	return path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(tok)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,232 @@
+package databridge
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const partnerSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["id", "email"],
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"email": {"type": "string", "format": "email"},
+		"code": {"type": "string", "pattern": "^[A-Z]{3}$"},
+		"tier": {"enum": ["gold", "silver"]},
+		"address": {"$ref": "#/$defs/address"}
+	},
+	"additionalProperties": false,
+	"$defs": {
+		"address": {
+			"type": "object",
+			"required": ["city"],
+			"properties": {"city": {"type": "string", "minLength": 2}}
+		}
+	}
+}`
+
+type partnerRecord struct {
+	ID      int64  `json:"id"`
+	Email   string `json:"email"`
+	Code    string `json:"code"`
+	Tier    string `json:"tier"`
+	Address struct {
+		City string `json:"city"`
+	} `json:"address"`
+}
+
+func violations(t *testing.T, err error) []string {
+	t.Helper()
+	var se *SchemaError
+	if !errors.As(err, &se) || !errors.Is(err, ErrSchemaViolation) {
+		t.Fatalf("err = %v, want a *SchemaError", err)
+	}
+	out := make([]string, len(se.Violations))
+	for i, v := range se.Violations {
+		out[i] = v.Path + " " + v.Keyword
+	}
+	return out
+}
+
+func TestWithSchemaJSON(t *testing.T) {
+	opt := WithSchema([]byte(partnerSchema))
+	var r partnerRecord
+	if err := TransformToStructUniversal(`{"id":7,"email":"a@example.com","code":"ABC","tier":"gold","address":{"city":"Oslo"}}`, &r, opt); err != nil {
+		t.Fatalf("valid input: %v", err)
+	}
+	if r.ID != 7 || r.Address.City != "Oslo" {
+		t.Fatalf("decoded %+v", r)
+	}
+
+	err := TransformToStructUniversal(`{"id":0,"email":"nope","code":"abcd","tier":"bronze","address":{"city":"X"},"extra/key":1}`, &r, opt)
+	got := violations(t, err)
+	want := []string{
+		"/address/city minLength",
+		"/code pattern",
+		"/email format",
+		"/extra~1key additionalProperties",
+		"/id minimum",
+		"/tier enum",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("violations = %v, want %v", got, want)
+	}
+
+	err = TransformToStructUniversal(`{"id":"seven","address":{}}`, &r, opt)
+	got = violations(t, err)
+	want = []string{"/email required", "/address/city required", "/id type"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("violations = %v, want %v", got, want)
+	}
+	if !strings.Contains(err.Error(), "/id: got string, want integer") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestWithSchemaTextValues(t *testing.T) {
+	type Addr struct {
+		Zip string `json:"zip"`
+		X   int    `json:"x"`
+	}
+	opt := WithSchema([]byte(`{"properties":{"zip":{"type":"string","pattern":"^[0-9]{5}$"},"x":{"type":"integer","maximum":5},"on":{"enum":["yes","true"]},"loc":{"properties":{"code":{"type":"string","minLength":3}}}}}`))
+
+	// numeric-looking CSV and form values are strings where the schema says so
+	var rows []Addr
+	if err := TransformToStructUniversal("zip,x,on,loc.code\n01234,1,true,007\n", &rows, opt); err != nil {
+		t.Fatalf("csv with string zip rejected: %v", err)
+	}
+	var a Addr
+	if err := TransformToStructUniversal(url.Values{"zip": {"01234"}, "x": {"2"}, "loc.code": {"007"}}, &a, opt); err != nil {
+		t.Fatalf("form with string zip rejected: %v", err)
+	}
+	if err := TransformToStructUniversal("zip=01234&x=3", &a, opt); err != nil {
+		t.Fatalf("form body with string zip rejected: %v", err)
+	}
+
+	// the other keywords still see the right reading
+	err := TransformToStructUniversal("zip,x\n1234,9\n", &rows, opt)
+	if got := violations(t, err); !reflect.DeepEqual(got, []string{"/0/x maximum", "/0/zip pattern"}) {
+		t.Fatalf("violations = %v", got)
+	}
+	err = TransformToStructUniversal(url.Values{"zip": {"01234"}, "x": {"abc"}}, &a, opt)
+	if got := violations(t, err); !reflect.DeepEqual(got, []string{"/x type"}) {
+		t.Fatalf("violations = %v", got)
+	}
+}
+
+func TestWithSchemaOtherFormats(t *testing.T) {
+	opt := WithSchema([]byte(partnerSchema))
+
+	// CSV: each record is validated, paths start with its index
+	var rows []partnerRecord
+	err := TransformToStructUniversal("id,email\n1,a@example.com\nx,bad\n", &rows, opt)
+	got := violations(t, err)
+	if want := []string{"/1/email format", "/1/id type"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("violations = %v, want %v", got, want)
+	}
+
+	// forms
+	var r partnerRecord
+	err = TransformToStructUniversal(url.Values{"id": {"-4"}, "email": {"a@example.com"}}, &r, opt)
+	if got := violations(t, err); !reflect.DeepEqual(got, []string{"/id minimum"}) {
+		t.Fatalf("violations = %v", got)
+	}
+
+	// YAML
+	err = TransformToStructUniversal("id: 3\nemail: a@example.com\ntier: bronze\n", &r, opt, WithYAML(true))
+	if got := violations(t, err); !reflect.DeepEqual(got, []string{"/tier enum"}) {
+		t.Fatalf("violations = %v", got)
+	}
+
+	// an array schema validates the records as a whole
+	list := WithSchema([]byte(`{"type":"array","maxItems":1,"items":{"required":["id"]}}`))
+	err = TransformToStructUniversal("id,email\n1,a@example.com\n2,b@example.com\n", &rows, list)
+	if got := violations(t, err); !reflect.DeepEqual(got, []string{" maxItems"}) {
+		t.Fatalf("violations = %v", got)
+	}
+}
+
+func TestWithSchemaInvalid(t *testing.T) {
+	var r partnerRecord
+	for _, schema := range []string{`{"type":`, `{"$ref":"other.json#/x"}`, `{"$ref":"#/$defs/missing"}`, `{"pattern":"("}`} {
+		err := TransformToStructUniversal(`{"id":1}`, &r, WithSchema([]byte(schema)))
+		if err == nil || !strings.Contains(err.Error(), "invalid schema") {
+			t.Fatalf("%s: err = %v", schema, err)
+		}
+	}
+}
+
+func TestWithSchemaFromSchemaFor(t *testing.T) {
+	b, err := json.Marshal(SchemaFor[schemaUser]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var u schemaUser
+	err = TransformToStructUniversal(`{"id":300,"home":{"city":"X"},"tags":["a","c"],"self":{"id":1}}`, &u, WithSchema(b))
+	got := violations(t, err)
+	want := []string{"/home/city minLength", "/id maximum", "/tags/1 enum"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("violations = %v, want %v", got, want)
+	}
+}
+
+func TestSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		schema string
+		value  string
+		want   []string // "path keyword"
+	}{
+		{`true`, `1`, nil},
+		{`false`, `1`, []string{" false"}},
+		{`{"type":"number"}`, `3`, nil},
+		{`{"type":"integer"}`, `3.5`, []string{" type"}},
+		{`{"type":["string","null"]}`, `null`, nil},
+		{`{"const":{"a":[1,2]}}`, `{"a":[1,2.0]}`, nil},
+		{`{"const":null}`, `0`, []string{" const"}},
+		{`{"multipleOf":0.5}`, `1.25`, []string{" multipleOf"}},
+		{`{"exclusiveMaximum":3}`, `3`, []string{" exclusiveMaximum"}},
+		{`{"maxLength":2}`, `"héé"`, []string{" maxLength"}},
+		{`{"anyOf":[{"type":"string"},{"minimum":5}]}`, `3`, []string{" anyOf"}},
+		{`{"oneOf":[{"type":"integer"},{"minimum":0}]}`, `3`, []string{" oneOf"}},
+		{`{"not":{"type":"string"}}`, `"x"`, []string{" not"}},
+		{`{"allOf":[{"minimum":1},{"maximum":2}]}`, `3`, []string{" maximum"}},
+		{`{"if":{"required":["a"]},"then":{"required":["b"]},"else":{"required":["c"]}}`, `{"a":1}`, []string{"/b required"}},
+		{`{"if":{"required":["a"]},"then":{"required":["b"]},"else":{"required":["c"]}}`, `{}`, []string{"/c required"}},
+		{`{"prefixItems":[{"type":"string"}],"items":{"type":"integer"}}`, `["a",1,"b"]`, []string{"/2 type"}},
+		{`{"uniqueItems":true}`, `[1,{"a":1},1.0]`, []string{" uniqueItems"}},
+		{`{"contains":{"type":"string"},"maxContains":1}`, `["a","b"]`, []string{" maxContains"}},
+		{`{"contains":{"type":"string"}}`, `[1]`, []string{" contains"}},
+		{`{"minProperties":2,"propertyNames":{"maxLength":1}}`, `{"ab":1}`, []string{" minProperties", "/ab maxLength"}},
+		{`{"patternProperties":{"^n_":{"type":"number"}},"additionalProperties":{"type":"string"}}`, `{"n_a":"x","b":2}`, []string{"/b type", "/n_a type"}},
+		{`{"dependentRequired":{"card":["cvv"]}}`, `{"card":"x"}`, []string{"/cvv dependentRequired"}},
+		{`{"dependentSchemas":{"card":{"required":["zip"]}}}`, `{"card":"x"}`, []string{"/zip required"}},
+		{`{"$defs":{"n":{"type":"object","properties":{"next":{"$ref":"#/$defs/n"},"v":{"type":"integer"}}}},"$ref":"#/$defs/n"}`, `{"next":{"next":{"v":"x"}}}`, []string{"/next/next/v type"}},
+		{`{"properties":{"a b":{"$ref":"#/$defs/a%20b"}},"$defs":{"a b":{"type":"string"}}}`, `{"a b":1}`, []string{"/a b type"}},
+		{`{"format":"uuid"}`, `"123e4567-e89b-12d3-a456-426614174000"`, nil},
+		{`{"format":"date-time"}`, `"2024-02-30T10:00:00Z"`, []string{" format"}},
+		{`{"format":"ipv4"}`, `"::1"`, []string{" format"}},
+		{`{"format":"custom"}`, `"anything"`, nil},
+	}
+	for _, tt := range tests {
+		s, err := compileSchema([]byte(tt.schema))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.schema, err)
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(tt.value), &v); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, vi := range s.validate(v, "", nil) {
+			got = append(got, vi.Path+" "+vi.Keyword)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s with %s: got %v, want %v", tt.schema, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package databridge
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// WithSlog sends structured events to logger, alongside any WithLogger func:
+//
+//   - Debug "databridge: format detected" (format), "databridge: fast path taken"
+//     and "databridge: fast path skipped" (reason)
+//   - Info "databridge: unmatched keys" (record, keys) and "databridge: fuzzy key
+//     match" (key, field)
+//   - Warn "databridge: key collision" (type, field, sources, winner) and
+//     "databridge: best-effort conversion" (error)
+//   - Error "databridge: record failed" (record, error)
+//
+// record is the record's path in the output: "" for a single object, "[i]" for
+// rows. Events are logged with the context of TransformContext.
+func WithSlog(logger *slog.Logger) Option {
+	return func(c *config) { c.slog = logger }
+}
+
+// logEvent logs a structured event when WithSlog is set and the level enabled.
+// Callers on per-record paths check c.slog first to avoid building attributes.
+func (c *config) logEvent(level slog.Level, msg string, attrs ...slog.Attr) {
+	if c.slog == nil || !c.slog.Enabled(c.ctx, level) {
+		return
+	}
+	c.slog.LogAttrs(c.ctx, level, "databridge: "+msg, attrs...)
+}
+
+// formatDetected reports the input format to the explain report, the logger and
+// the observer.
+func (c *config) formatDetected(format string) {
+	if c.explain != nil {
+		c.explain.Format = format
+	}
+	c.logEvent(slog.LevelDebug, "format detected", slog.String("format", format))
+	if c.observer != nil {
+		c.observer.OnFormatDetected(c.ctx, format)
+	}
+}
+
+// rowDone reports a row mapped (err == nil) or failed to the observer and logs
+// failures.
+func (c *config) rowDone(row int, err error) {
+	if c.observer != nil {
+		c.observer.OnRow(c.ctx, row, err)
+	}
+	if err != nil && c.slog != nil {
+		c.logEvent(slog.LevelError, "record failed", slog.String("record", fmt.Sprintf("[%d]", row)), slog.Any("error", err))
+	}
+}
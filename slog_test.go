@@ -0,0 +1,80 @@
+package databridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// slogEvents decodes the JSON lines written by a slog.JSONHandler.
+func slogEvents(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var ev map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("bad log line %q: %v", line, err)
+		}
+		out = append(out, ev)
+	}
+	return out
+}
+
+func findEvent(evs []map[string]interface{}, msg string) map[string]interface{} {
+	for _, ev := range evs {
+		if ev["msg"] == "databridge: "+msg {
+			return ev
+		}
+	}
+	return nil
+}
+
+func TestWithSlog(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var p Person
+	if err := TransformToStructUniversal(`{"name":"a","NAME":"b","age":"x1","extra":1}`, &p, WithSlog(logger)); err == nil {
+		t.Fatalf("expected decode error for age")
+	}
+	evs := slogEvents(t, &buf)
+	if ev := findEvent(evs, "format detected"); ev == nil || ev["level"] != "DEBUG" || ev["format"] != "json" {
+		t.Fatalf("format event = %v", ev)
+	}
+	if ev := findEvent(evs, "fast path skipped"); ev == nil || ev["reason"] != "options require mapping" {
+		t.Fatalf("fast path event = %v", ev)
+	}
+	if ev := findEvent(evs, "unmatched keys"); ev == nil || ev["level"] != "INFO" || ev["record"] != "" || ev["keys"].([]interface{})[0] != "extra" {
+		t.Fatalf("unmatched event = %v", ev)
+	}
+	if ev := findEvent(evs, "key collision"); ev == nil || ev["level"] != "WARN" || ev["winner"] != "name" || ev["field"] != "name" {
+		t.Fatalf("collision event = %v", ev)
+	}
+	if ev := findEvent(evs, "best-effort conversion"); ev == nil || ev["level"] != "WARN" {
+		t.Fatalf("fallback event = %v", ev)
+	}
+
+	buf.Reset()
+	if _, err := FromJSONString[Person](`{"name":"a"}`, WithSlog(logger)); err != nil {
+		t.Fatalf("FromJSON: %v", err)
+	}
+	if findEvent(slogEvents(t, &buf), "fast path taken") == nil {
+		t.Fatalf("no fast path event: %s", buf.String())
+	}
+
+	buf.Reset()
+	var rows []Person
+	_ = TransformToStructUniversal("name,age\na,1\nb,1e99\n", &rows, WithSlog(logger))
+	if ev := findEvent(slogEvents(t, &buf), "record failed"); ev == nil || ev["level"] != "ERROR" || ev["record"] != "[1]" || !strings.Contains(ev["error"].(string), "overflows") {
+		t.Fatalf("record event = %v", ev)
+	}
+}
@@ -0,0 +1,139 @@
+package databridge
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// StringPolicy describes how string values are sanitized on their way into
+// string fields (including pointers, slices and interfaces holding strings).
+type StringPolicy struct {
+	Trim          bool // remove leading and trailing Unicode whitespace (including NBSP)
+	CollapseSpace bool // replace runs of Unicode whitespace with a single space
+	StripControl  bool // remove control characters (except \t, \n, \r) and zero-width/format characters
+	NFC           bool // apply Unicode NFC normalization
+	// MaxLength limits the length in runes (0 = unlimited). Longer values fail with
+	// a FieldError unless Truncate is set.
+	MaxLength int
+	Truncate  bool
+	// EmptyAsNil turns empty strings (after sanitization) into nil for nullable
+	// fields: pointers, slices, maps and interfaces.
+	EmptyAsNil bool
+}
+
+// WithStringPolicy sanitizes string values during coercion. A field can replace
+// the policy with `databridge:"str='trim,collapse,control,nfc,emptynil,max=40,truncate'"`
+// or opt out with `databridge:"str=raw"`.
+func WithStringPolicy(p StringPolicy) Option {
+	return func(c *config) { c.StringPolicy = &p }
+}
+
+// parseStringPolicy parses the value of a `str=` tag option.
+func parseStringPolicy(spec string) *StringPolicy {
+	p := &StringPolicy{}
+	for _, opt := range strings.Split(spec, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "trim":
+			p.Trim = true
+		case "collapse":
+			p.CollapseSpace = true
+		case "control":
+			p.StripControl = true
+		case "nfc":
+			p.NFC = true
+		case "emptynil":
+			p.EmptyAsNil = true
+		case "truncate":
+			p.Truncate = true
+		case "max":
+			p.MaxLength, _ = strconv.Atoi(val)
+		}
+	}
+	return p
+}
+
+// stringPolicyFor returns the policy for a field: its tag override, else the
+// configured policy. nil means strings are left as they are.
+func (c *config) stringPolicyFor(tag fieldTag) *StringPolicy {
+	if tag.Str != nil {
+		return tag.Str
+	}
+	return c.StringPolicy
+}
+
+// apply sanitizes s according to the policy.
+func (p *StringPolicy) apply(s string) (string, error) {
+	if p.StripControl {
+		s = strings.Map(func(r rune) rune {
+			if r == '\t' || r == '\n' || r == '\r' {
+				return r
+			}
+			if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+				return -1
+			}
+			return r
+		}, s)
+	}
+	if p.NFC {
+		s = norm.NFC.String(s)
+	}
+	if p.CollapseSpace {
+		s = collapseSpace(s)
+	}
+	if p.Trim {
+		s = strings.TrimFunc(s, unicode.IsSpace)
+	}
+	if p.MaxLength > 0 && utf8.RuneCountInString(s) > p.MaxLength {
+		if !p.Truncate {
+			return "", fmt.Errorf("string of %d characters exceeds maximum length %d", utf8.RuneCountInString(s), p.MaxLength)
+		}
+		s = string([]rune(s)[:p.MaxLength])
+	}
+	return s, nil
+}
+
+func collapseSpace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inSpace := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !inSpace {
+				b.WriteByte(' ')
+			}
+			inSpace = true
+			continue
+		}
+		inSpace = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// applyStringPolicy sanitizes a string headed for a field of type t. nullable
+// reports that the value should become nil under EmptyAsNil.
+func applyStringPolicy(s string, t reflect.Type, p *StringPolicy) (out string, null bool, err error) {
+	base := t
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	if base.Kind() == reflect.String || base.Kind() == reflect.Interface {
+		if s, err = p.apply(s); err != nil {
+			return "", false, err
+		}
+	}
+	if p.EmptyAsNil && s == "" {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			return "", true, nil
+		}
+	}
+	return s, false, nil
+}
@@ -0,0 +1,58 @@
+package databridge
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestStringPolicy(t *testing.T) {
+	type Profile struct {
+		Name     string   `json:"name"`
+		Bio      *string  `json:"bio"`
+		Tags     []string `json:"tags"`
+		Nickname *string  `json:"nickname"`
+		Code     string   `json:"code" databridge:"str=raw"`
+		Title    string   `json:"title" databridge:"str='trim,max=5,truncate'"`
+	}
+	policy := WithStringPolicy(StringPolicy{Trim: true, CollapseSpace: true, StripControl: true, NFC: true, MaxLength: 20, EmptyAsNil: true})
+	in := url.Values{
+		"name":     {"  Jose\u0301\u200b \u00a0 García \t"},
+		"bio":      {"\u200b\u00a0"},
+		"tags":     {" a ", "b \u00a0c"},
+		"nickname": {""},
+		"code":     {" x\u200b "},
+		"title":    {"  Doctor  "},
+	}
+	var p Profile
+	if err := TransformToStructUniversal(in, &p, policy); err != nil {
+		t.Fatalf("policy transform failed: %v", err)
+	}
+	if p.Name != "José García" {
+		t.Fatalf("name = %q", p.Name)
+	}
+	if p.Bio != nil || p.Nickname != nil {
+		t.Fatalf("expected empty strings as nil, got bio=%v nickname=%v", p.Bio, p.Nickname)
+	}
+	if len(p.Tags) != 2 || p.Tags[0] != "a" || p.Tags[1] != "b c" {
+		t.Fatalf("tags = %q", p.Tags)
+	}
+	if p.Code != " x\u200b " {
+		t.Fatalf("str=raw field was sanitized: %q", p.Code)
+	}
+	if p.Title != "Docto" {
+		t.Fatalf("title = %q", p.Title)
+	}
+}
+
+func TestStringPolicyMaxLength(t *testing.T) {
+	type Row struct {
+		Name string `json:"name"`
+	}
+	var rows []Row
+	err := TransformToStructUniversal("name,id\nok,1\ntoolong,2\n", &rows, WithStringPolicy(StringPolicy{MaxLength: 4}))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "[1].name" {
+		t.Fatalf("expected FieldError at [1].name, got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package databridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayouts are the layouts tried, in order, when a string is decoded
+// into a time.Time field and no per-field layout is set.
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// ParseTime parses s with the given layouts (DefaultTimeLayouts when none are
+// given). Values without a zone are interpreted in loc (UTC when loc is nil).
+// If no layout matches and s is numeric, it is read as a Unix epoch whose unit
+// (seconds, milliseconds, microseconds or nanoseconds) is chosen by magnitude.
+//
+// ParseTime is shared by the runtime coercion and by databridge-gen output.
+func ParseTime(s string, loc *time.Location, layouts ...string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	if len(layouts) == 0 {
+		layouts = DefaultTimeLayouts
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, s, loc); err == nil {
+			return t, true
+		}
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return epochIntToTime(i, loc), true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && epochInRange(f) {
+		return EpochToTime(f, loc), true
+	}
+	return time.Time{}, false
+}
+
+// EpochToTime converts a Unix epoch to a time in loc (UTC when nil). Values below
+// 1e11 are seconds, below 1e14 milliseconds, below 1e17 microseconds, and
+// nanoseconds otherwise; fractional seconds are honoured.
+func EpochToTime(v float64, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var t time.Time
+	switch abs := math.Abs(v); {
+	case abs < 1e11:
+		sec, frac := math.Modf(v)
+		t = time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+	case abs < 1e14:
+		t = time.UnixMilli(int64(v))
+	case abs < 1e17:
+		t = time.UnixMicro(int64(v))
+	default:
+		t = time.Unix(0, int64(v))
+	}
+	return t.In(loc)
+}
+
+// epochInRange reports whether EpochToTime can represent v: nanosecond epochs
+// must fit in an int64.
+func epochInRange(v float64) bool {
+	return v >= math.MinInt64 && v < math.MaxInt64 // MaxInt64 rounds up to 2^63 as a float
+}
+
+// epochIntToTime is EpochToTime for integers, without float rounding.
+func epochIntToTime(v int64, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	abs := v
+	if abs < 0 {
+		abs = -abs
+	}
+	var t time.Time
+	switch {
+	case abs < 1e11:
+		t = time.Unix(v, 0)
+	case abs < 1e14:
+		t = time.UnixMilli(v)
+	case abs < 1e17:
+		t = time.UnixMicro(v)
+	default:
+		t = time.Unix(0, v)
+	}
+	return t.In(loc)
+}
+
+// ParseDuration parses Go duration strings ("1h30m", "250ms") and plain numbers,
+// which are read as seconds ("90", "1.5").
+func ParseDuration(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return secondsToDuration(f)
+	}
+	return 0, false
+}
+
+// maxDurationSeconds is the largest whole number of seconds a time.Duration holds.
+const maxDurationSeconds = int64(math.MaxInt64 / time.Second)
+
+// secondsToDuration converts seconds to a duration; ok is false when f is out
+// of the range of time.Duration.
+func secondsToDuration(f float64) (d time.Duration, ok bool) {
+	ns := math.Round(f * float64(time.Second))
+	if !(ns >= math.MinInt64 && ns < math.MaxInt64) {
+		return 0, false
+	}
+	return time.Duration(ns), true
+}
+
+// WithTimeLayouts adds layouts tried before DefaultTimeLayouts when decoding
+// strings into time.Time fields.
+func WithTimeLayouts(layouts ...string) Option {
+	return func(c *config) { c.TimeLayouts = append(c.TimeLayouts, layouts...) }
+}
+
+// WithTimeLocation sets the location used for time strings without a zone and
+// for Unix epochs. The default is UTC.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(c *config) { c.TimeLocation = loc }
+}
+
+// timeLayoutsFor returns the layouts to try for a field: its tag layout when set,
+// otherwise the configured layouts followed by the defaults.
+func timeLayoutsFor(tag fieldTag, cfg *config) []string {
+	if tag.Layout != "" {
+		return []string{tag.Layout}
+	}
+	if len(cfg.TimeLayouts) == 0 {
+		return DefaultTimeLayouts
+	}
+	return append(append([]string(nil), cfg.TimeLayouts...), DefaultTimeLayouts...)
+}
+
+// coerceTime converts strings and epoch numbers into time.Time.
+func coerceTime(v interface{}, tag fieldTag, cfg *config) (interface{}, error) {
+	switch x := v.(type) {
+	case string:
+		if tt, ok := ParseTime(x, cfg.TimeLocation, timeLayoutsFor(tag, cfg)...); ok {
+			return tt, nil
+		}
+		return nil, fmt.Errorf("cannot parse %q as time", x)
+	case int64:
+		return epochIntToTime(x, cfg.TimeLocation), nil
+	case uint64:
+		if x > math.MaxInt64 {
+			return nil, fmt.Errorf("epoch %d overflows time.Time", x)
+		}
+		return epochIntToTime(int64(x), cfg.TimeLocation), nil
+	case float64:
+		if !epochInRange(x) {
+			return nil, fmt.Errorf("epoch %v overflows time.Time", x)
+		}
+		return EpochToTime(x, cfg.TimeLocation), nil
+	case json.Number:
+		n, err := jsonNumberValue(x)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s as time", x)
+		}
+		return coerceTime(n, tag, cfg)
+	}
+	return v, nil
+}
+
+// coerceDuration converts duration strings and numeric seconds into time.Duration.
+func coerceDuration(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case string:
+		if d, ok := ParseDuration(x); ok {
+			return int64(d), nil
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(x), 64); err == nil {
+			return nil, fmt.Errorf("%s seconds overflows time.Duration", strings.TrimSpace(x))
+		}
+		return nil, fmt.Errorf("cannot parse %q as duration", x)
+	case int64:
+		if x > maxDurationSeconds || x < -maxDurationSeconds {
+			return nil, fmt.Errorf("%d seconds overflows time.Duration", x)
+		}
+		return int64(time.Duration(x) * time.Second), nil
+	case uint64:
+		if x > uint64(maxDurationSeconds) {
+			return nil, fmt.Errorf("%d seconds overflows time.Duration", x)
+		}
+		return int64(time.Duration(x) * time.Second), nil
+	case float64:
+		d, ok := secondsToDuration(x)
+		if !ok {
+			return nil, fmt.Errorf("%v seconds overflows time.Duration", x)
+		}
+		return int64(d), nil
+	case json.Number:
+		n, err := jsonNumberValue(x)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s as duration", x)
+		}
+		return coerceDuration(n)
+	}
+	return v, nil
+}
+
+// jsonNumberValue returns n as an int64, a uint64 or a float64.
+func jsonNumberValue(n json.Number) (interface{}, error) {
+	v := coerceNumberValue(n)
+	if _, big := v.(json.Number); big {
+		return n.Float64()
+	}
+	return v, nil
+}
@@ -0,0 +1,118 @@
+package databridge
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeLayoutsAndLocation(t *testing.T) {
+	type S struct {
+		Day     time.Time `json:"day"`
+		Stamp   time.Time `json:"stamp"`
+		Printed time.Time `json:"printed" databridge:"layout='Jan 2, 2006'"`
+	}
+	loc := time.FixedZone("CET", 3600)
+	in := `{"day":"25/12/2023","stamp":"Jan 2 2006 3:04PM","printed":"Mar 4, 2021"}`
+	s, err := Transform[S](in, WithTimeLayouts("02/01/2006", "Jan 2 2006 3:04PM"), WithTimeLocation(loc))
+	if err != nil {
+		t.Fatalf("custom layouts failed: %v", err)
+	}
+	if s.Day.Year() != 2023 || s.Day.Month() != time.December || s.Day.Day() != 25 {
+		t.Fatalf("unexpected day: %v", s.Day)
+	}
+	if _, off := s.Day.Zone(); off != 3600 {
+		t.Fatalf("expected day in CET, got %v", s.Day)
+	}
+	if s.Stamp.Hour() != 15 || s.Stamp.Minute() != 4 {
+		t.Fatalf("unexpected stamp: %v", s.Stamp)
+	}
+	if s.Printed.Year() != 2021 || s.Printed.Month() != time.March {
+		t.Fatalf("unexpected printed: %v", s.Printed)
+	}
+}
+
+func TestTimeEpochs(t *testing.T) {
+	type S struct {
+		Sec   time.Time  `json:"sec"`
+		Milli time.Time  `json:"milli"`
+		Str   time.Time  `json:"str"`
+		Ptr   *time.Time `json:"ptr"`
+	}
+	in := `{"sec":1700000000,"milli":1700000000123,"str":"1700000000","ptr":1700000000.5}`
+	s, err := Transform[S](in)
+	if err != nil {
+		t.Fatalf("epoch parse failed: %v", err)
+	}
+	want := time.Unix(1700000000, 0).UTC()
+	if !s.Sec.Equal(want) || !s.Str.Equal(want) {
+		t.Fatalf("unexpected epoch seconds: %v / %v", s.Sec, s.Str)
+	}
+	if !s.Milli.Equal(time.UnixMilli(1700000000123)) {
+		t.Fatalf("unexpected epoch millis: %v", s.Milli)
+	}
+	if s.Ptr == nil || s.Ptr.Nanosecond() != 500000000 {
+		t.Fatalf("unexpected fractional epoch: %v", s.Ptr)
+	}
+}
+
+func TestDurationParsing(t *testing.T) {
+	type S struct {
+		Timeout time.Duration   `json:"timeout"`
+		Retry   time.Duration   `json:"retry"`
+		Steps   []time.Duration `json:"steps"`
+	}
+	s, err := Transform[S](url.Values{"timeout": {"1h30m"}, "retry": {"90"}, "steps": {"1.5", "250ms"}})
+	if err != nil {
+		t.Fatalf("duration parse failed: %v", err)
+	}
+	if s.Timeout != 90*time.Minute || s.Retry != 90*time.Second {
+		t.Fatalf("unexpected durations: %+v", s)
+	}
+	if len(s.Steps) != 2 || s.Steps[0] != 1500*time.Millisecond || s.Steps[1] != 250*time.Millisecond {
+		t.Fatalf("unexpected steps: %v", s.Steps)
+	}
+
+	_, err = Transform[S](`{"timeout":"soon"}`)
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "timeout" {
+		t.Fatalf("expected FieldError for timeout, got %v", err)
+	}
+}
+
+func TestParseTimeDefaults(t *testing.T) {
+	for _, s := range []string{"2024-12-01T10:11:12Z", "2024-12-01 10:11:12", "2024-12-01", "1733047872"} {
+		if _, ok := ParseTime(s, nil); !ok {
+			t.Fatalf("ParseTime(%q) failed", s)
+		}
+	}
+	if _, ok := ParseTime("yesterday", nil); ok {
+		t.Fatal("expected ParseTime to reject non-time input")
+	}
+}
+
+func TestDurationAndTimeRange(t *testing.T) {
+	type S struct {
+		Retry time.Duration `json:"retry"`
+		At    time.Time     `json:"at"`
+	}
+	s, err := Transform[S](map[string]interface{}{"retry": json.Number("1.5"), "at": json.Number("1733047872")})
+	if err != nil || s.Retry != 1500*time.Millisecond || s.At.Unix() != 1733047872 {
+		t.Fatalf("json.Number not converted: %+v err=%v", s, err)
+	}
+	for _, in := range []string{`{"retry":10000000000}`, `{"retry":1e30}`, `{"retry":"1e30"}`, `{"retry":99999999999999999999999}`} {
+		_, err := Transform[S](in)
+		var fe *FieldError
+		if !errors.As(err, &fe) || fe.Path != "retry" || !strings.Contains(err.Error(), "overflows time.Duration") {
+			t.Fatalf("%s: expected overflow FieldError, got %v", in, err)
+		}
+	}
+	_, err = Transform[S](`{"at":1e300}`)
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "at" {
+		t.Fatalf("expected FieldError for out-of-range epoch, got %v", err)
+	}
+}
@@ -5,23 +5,9 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 )
 
 func _db_parseBool(s string) (bool, error) { return strconv.ParseBool(strings.TrimSpace(s)) }
-func _db_parseTimeFlexible(s string) (time.Time, bool) {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return time.Time{}, false
-	}
-	formats := []string{time.RFC3339Nano, time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
-	for _, f := range formats {
-		if t, err := time.Parse(f, s); err == nil {
-			return t, true
-		}
-	}
-	return time.Time{}, false
-}
 func BindOrderFromForm(vals url.Values) (Order, error) {
 	out := Order{}
 	if s := vals.Get("order_id"); s != "" {
@@ -49,7 +35,7 @@ func BindOrderFromForm(vals url.Values) (Order, error) {
 		}
 	}
 	if s := vals.Get("created_at"); s != "" {
-		if tt, ok := _db_parseTimeFlexible(s); ok {
+		if tt, ok := ParseTime(s, nil); ok {
 			out.CreatedAt = tt
 		}
 	}
@@ -81,7 +67,7 @@ func BindUserFromForm(vals url.Values) (User, error) {
 		}
 	}
 	if s := vals.Get("created_at"); s != "" {
-		if tt, ok := _db_parseTimeFlexible(s); ok {
+		if tt, ok := ParseTime(s, nil); ok {
 			out.CreatedAt = tt
 		}
 	}